@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/apikey/keystore"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+var apiKeyCmd = &cobra.Command{
+	Use:   "apikey",
+	Short: "Manage the API keys of the hub",
+}
+
+func init() {
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create an API key, it is only printed once",
+		Args:  cobra.NoArgs,
+		RunE:  RunAPIKeyCreate,
+	}
+
+	createCmd.Flags().String("name", "", "name of the key owner")
+	createCmd.Flags().Int("crawl-limit", 0, "crawl requests per minute, 0 for the default")
+	createCmd.Flags().Int("read-limit", 0, "read requests per minute, 0 for the default")
+	_ = createCmd.MarkFlagRequired("name")
+
+	usageCmd := &cobra.Command{
+		Use:   "usage <id>",
+		Short: "Show the daily usage of an API key",
+		Args:  cobra.ExactArgs(1),
+		RunE:  RunAPIKeyUsage,
+	}
+
+	usageCmd.Flags().Int("days", 7, "number of days to show")
+
+	apiKeyCmd.AddCommand(createCmd, usageCmd)
+	apiKeyCmd.AddCommand(&cobra.Command{
+		Use:   "revoke <id>",
+		Short: "Revoke an API key",
+		Args:  cobra.ExactArgs(1),
+		RunE:  RunAPIKeyRevoke,
+	})
+	apiKeyCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List the API keys",
+		Args:  cobra.NoArgs,
+		RunE:  RunAPIKeyList,
+	})
+}
+
+func RunAPIKeyCreate(cmd *cobra.Command, args []string) error {
+	name, _ := cmd.Flags().GetString("name")
+	crawlLimit, _ := cmd.Flags().GetInt("crawl-limit")
+	readLimit, _ := cmd.Flags().GetInt("read-limit")
+
+	if crawlLimit < 0 || readLimit < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+
+	key, keyModel, err := keystore.Create(name, crawlLimit, readLimit)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("id:  %s\nkey: %s\n", keyModel.ID, key)
+
+	return nil
+}
+
+func RunAPIKeyRevoke(cmd *cobra.Command, args []string) error {
+	id, err := uuid.Parse(args[0])
+	if err != nil {
+		return err
+	}
+
+	return keystore.Revoke(id)
+}
+
+func RunAPIKeyList(cmd *cobra.Command, args []string) error {
+	keys, err := keystore.List()
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tNAME\tCRAWL LIMIT\tREAD LIMIT\tCREATED\tREVOKED")
+
+	for _, key := range keys {
+		revokedAt := "-"
+		if key.RevokedAt.Valid {
+			revokedAt = key.RevokedAt.Time.Format("2006-01-02 15:04:05")
+		}
+
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%d\t%s\t%s\n",
+			key.ID, key.Name, key.CrawlLimit, key.ReadLimit, key.CreatedAt.Format("2006-01-02 15:04:05"), revokedAt)
+	}
+
+	return writer.Flush()
+}
+
+func RunAPIKeyUsage(cmd *cobra.Command, args []string) error {
+	id, err := uuid.Parse(args[0])
+	if err != nil {
+		return err
+	}
+
+	days, _ := cmd.Flags().GetInt("days")
+
+	usageList, err := keystore.GetUsage(context.Background(), id, days)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "DATE\tCRAWL\tCRAWL LIMITED\tREAD\tREAD LIMITED")
+
+	for _, usage := range usageList {
+		fmt.Fprintf(writer, "%s\t%d\t%d\t%d\t%d\n", usage.Date, usage.Crawl, usage.CrawlLimited, usage.Read, usage.ReadLimited)
+	}
+
+	return writer.Flush()
+}
@@ -0,0 +1,119 @@
+package activitypub
+
+const (
+	ContentType    = "application/activity+json"
+	JRDContentType = "application/jrd+json"
+
+	ContextActivityStreams = "https://www.w3.org/ns/activitystreams"
+	ContextSecurity        = "https://w3id.org/security/v1"
+
+	Public = "https://www.w3.org/ns/activitystreams#Public"
+)
+
+const (
+	TypePerson                = "Person"
+	TypeNote                  = "Note"
+	TypeCreate                = "Create"
+	TypeFollow                = "Follow"
+	TypeAccept                = "Accept"
+	TypeUndo                  = "Undo"
+	TypeHashtag               = "Hashtag"
+	TypeImage                 = "Image"
+	TypeVideo                 = "Video"
+	TypeAudio                 = "Audio"
+	TypeDocument              = "Document"
+	TypeOrderedCollection     = "OrderedCollection"
+	TypeOrderedCollectionPage = "OrderedCollectionPage"
+)
+
+type Actor struct {
+	Context           []string   `json:"@context"`
+	ID                string     `json:"id"`
+	Type              string     `json:"type"`
+	PreferredUsername string     `json:"preferredUsername"`
+	Name              string     `json:"name,omitempty"`
+	Summary           string     `json:"summary,omitempty"`
+	URL               string     `json:"url,omitempty"`
+	Icon              *Image     `json:"icon,omitempty"`
+	Inbox             string     `json:"inbox"`
+	Outbox            string     `json:"outbox"`
+	PublicKey         *PublicKey `json:"publicKey,omitempty"`
+}
+
+type PublicKey struct {
+	ID           string `json:"id"`
+	Owner        string `json:"owner"`
+	PublicKeyPem string `json:"publicKeyPem"`
+}
+
+type Image struct {
+	Type      string `json:"type"`
+	MediaType string `json:"mediaType,omitempty"`
+	URL       string `json:"url"`
+}
+
+type Activity struct {
+	Context   any      `json:"@context,omitempty"`
+	ID        string   `json:"id"`
+	Type      string   `json:"type"`
+	Actor     string   `json:"actor"`
+	Published string   `json:"published,omitempty"`
+	To        []string `json:"to,omitempty"`
+	Object    any      `json:"object"`
+}
+
+type Note struct {
+	ID           string       `json:"id"`
+	Type         string       `json:"type"`
+	AttributedTo string       `json:"attributedTo"`
+	Name         string       `json:"name,omitempty"`
+	Content      string       `json:"content"`
+	Published    string       `json:"published"`
+	Updated      string       `json:"updated,omitempty"`
+	URL          string       `json:"url,omitempty"`
+	To           []string     `json:"to"`
+	Tag          []Tag        `json:"tag,omitempty"`
+	Attachment   []Attachment `json:"attachment,omitempty"`
+}
+
+type Tag struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type Attachment struct {
+	Type      string `json:"type"`
+	MediaType string `json:"mediaType,omitempty"`
+	URL       string `json:"url"`
+	Name      string `json:"name,omitempty"`
+}
+
+type OrderedCollection struct {
+	Context    string `json:"@context"`
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	TotalItems int64  `json:"totalItems"`
+	First      string `json:"first,omitempty"`
+}
+
+type OrderedCollectionPage struct {
+	Context      string     `json:"@context"`
+	ID           string     `json:"id"`
+	Type         string     `json:"type"`
+	PartOf       string     `json:"partOf"`
+	Next         string     `json:"next,omitempty"`
+	OrderedItems []Activity `json:"orderedItems"`
+}
+
+// WebFinger is a JSON Resource Descriptor defined in RFC 7033.
+type WebFinger struct {
+	Subject string          `json:"subject"`
+	Aliases []string        `json:"aliases,omitempty"`
+	Links   []WebFingerLink `json:"links"`
+}
+
+type WebFingerLink struct {
+	Rel  string `json:"rel"`
+	Type string `json:"type,omitempty"`
+	Href string `json:"href"`
+}
@@ -0,0 +1,65 @@
+package activitypub
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
+)
+
+// maxDrainedBody is read from a response so that the connection can be reused
+const maxDrainedBody = 4096
+
+// ActorURL returns the actor of an account instance on the hub at baseURL.
+func ActorURL(baseURL string, instance *rss3uri.PlatformInstance) string {
+	return fmt.Sprintf("%s/%s/%s/actor", baseURL, protocol.Version, url.PathEscape(instance.String()))
+}
+
+// ObjectURL converts an rss3 identifier to the url resolving it on the hub at baseURL.
+func ObjectURL(baseURL string, identifier string) string {
+	return fmt.Sprintf("%s/%s/%s", baseURL, protocol.Version, url.PathEscape(strings.TrimPrefix(identifier, fmt.Sprintf("%s://", rss3uri.Scheme))))
+}
+
+// KeyID returns the id of the public key of an actor.
+func KeyID(actorURL string) string {
+	return fmt.Sprintf("%s#main-key", actorURL)
+}
+
+// Deliver posts a signed activity to an inbox and returns the status code of the response,
+// any status of 400 or above is an error.
+func Deliver(ctx context.Context, client *http.Client, inbox string, keyID string, privateKey *rsa.PrivateKey, body []byte) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, inbox, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	request.Header.Set("Content-Type", ContentType)
+	request.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+
+	if err := Sign(request, body, keyID, privateKey); err != nil {
+		return 0, err
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+
+	defer response.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, maxDrainedBody))
+
+	if response.StatusCode >= http.StatusBadRequest {
+		return response.StatusCode, fmt.Errorf("deliver activity to %s: %s", inbox, response.Status)
+	}
+
+	return response.StatusCode, nil
+}
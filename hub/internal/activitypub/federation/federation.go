@@ -0,0 +1,325 @@
+// Package federation delivers the notes of account instances to their fediverse followers,
+// and the replies to the activities of the followers, through a queue in the database.
+package federation
+
+import (
+	"context"
+	"crypto/rsa"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/activitypub"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/netguard"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/service"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/stream"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/webhook"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/config"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	enqueueBatchLimit = 1000
+	deliverBatchLimit = 10
+
+	// enqueueLookback re-scans recently inserted notes, since concurrent transactions
+	// may commit in a different order than their created_at
+	enqueueLookback = 10 * time.Second
+	// enqueueInterval polls in case a notification was lost
+	enqueueInterval = 15 * time.Second
+	deliverInterval = time.Second
+	// deliveryLease hides a claimed delivery from other hubs until it is sent
+	deliveryLease = time.Minute
+)
+
+var ErrNotConfigured = errors.New("activitypub private key is not configured")
+
+var (
+	client = netguard.NewClient(10*time.Second, false)
+
+	privateKeyOnce sync.Once
+	privateKey     *rsa.PrivateKey
+	privateKeyErr  error
+)
+
+// PrivateKey returns the configured key signing the activities of all the actors.
+func PrivateKey() (*rsa.PrivateKey, error) {
+	privateKeyOnce.Do(func() {
+		if config.Config.Hub.ActivityPub.PrivateKey == "" {
+			privateKeyErr = ErrNotConfigured
+
+			return
+		}
+
+		privateKey, privateKeyErr = activitypub.ParsePrivateKey(config.Config.Hub.ActivityPub.PrivateKey)
+	})
+
+	return privateKey, privateKeyErr
+}
+
+// Run enqueues the new notes for the followers and sends due deliveries until the context is done,
+// it is safe to run on several hubs at once. Notes are only enqueued if the activitypub domain
+// is configured, the actor urls are unknown outside of requests otherwise.
+func Run(ctx context.Context) {
+	if _, err := PrivateKey(); err != nil {
+		logger.Infof("activitypub deliveries are disabled: %v", err)
+
+		return
+	}
+
+	baseURL := ""
+	if domain := config.Config.Hub.ActivityPub.Domain; domain != "" {
+		baseURL = fmt.Sprintf("https://%s", domain)
+	} else {
+		logger.Infof("activitypub domain is not configured, notes are not delivered to followers")
+	}
+
+	subscription := stream.Subscribe(nil)
+	defer stream.Unsubscribe(subscription)
+
+	enqueueTicker := time.NewTicker(enqueueInterval)
+	defer enqueueTicker.Stop()
+
+	deliverTicker := time.NewTicker(deliverInterval)
+	defer deliverTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-subscription.C:
+			enqueue(baseURL)
+		case <-enqueueTicker.C:
+			enqueue(baseURL)
+		case <-deliverTicker.C:
+			if err := DeliverDue(ctx); err != nil {
+				logger.Errorf("deliver activities: %v", err)
+			}
+		}
+	}
+}
+
+func enqueue(baseURL string) {
+	if baseURL == "" {
+		return
+	}
+
+	followers := make([]model.ActivityPubFollower, 0)
+	if err := database.DB.Find(&followers).Error; err != nil {
+		logger.Errorf("find activitypub followers: %v", err)
+
+		return
+	}
+
+	for _, follower := range followers {
+		if err := Enqueue(baseURL, follower); err != nil {
+			logger.Errorf("enqueue activities of %s for %s: %v", follower.Instance, follower.Actor, err)
+		}
+	}
+}
+
+// Enqueue creates the Create activities of the notes inserted since the follower cursor,
+// a note is only enqueued once per inbox.
+func Enqueue(baseURL string, follower model.ActivityPubFollower) error {
+	instance, err := rss3uri.ParseInstance(follower.Instance)
+	if err != nil {
+		return err
+	}
+
+	platformInstance, ok := instance.(*rss3uri.PlatformInstance)
+	if !ok {
+		return rss3uri.ErrInvalidIdentity
+	}
+
+	owner := strings.ToLower(rss3uri.New(instance).String())
+	actorURL := activitypub.ActorURL(baseURL, platformInstance)
+	backlog := false
+
+	for {
+		internalDB := database.DB.Where("owner = ?", owner)
+
+		if backlog {
+			internalDB = internalDB.Where("(created_at, identifier) > (?, ?)", follower.CursorCreatedAt, follower.CursorIdentifier)
+		} else {
+			internalDB = internalDB.Where("created_at > ?", follower.CursorCreatedAt.Add(-enqueueLookback))
+		}
+
+		notes := make([]model.Note, 0)
+		if err := internalDB.
+			Order("created_at").
+			Order("identifier").
+			Limit(enqueueBatchLimit).
+			Find(&notes).Error; err != nil {
+			return err
+		}
+
+		if len(notes) == 0 {
+			return nil
+		}
+
+		itemList, _, _, err := service.FormatProtocolItemByNote(notes)
+		if err != nil {
+			return err
+		}
+
+		deliveries := make([]model.ActivityPubDelivery, 0, len(notes))
+
+		for i, note := range notes {
+			activity := activitypub.NewCreateActivity(actorURL, activitypub.ObjectURL(baseURL, note.Identifier), itemList[i])
+			activity.Context = activitypub.ContextActivityStreams
+
+			payload, err := json.Marshal(activity)
+			if err != nil {
+				return err
+			}
+
+			deliveries = append(deliveries, model.ActivityPubDelivery{
+				Instance:       follower.Instance,
+				Inbox:          follower.Inbox,
+				NoteIdentifier: sql.NullString{String: note.Identifier, Valid: true},
+				Activity:       payload,
+				Status:         webhook.StatusPending,
+				NextAttemptAt:  time.Now(),
+			})
+
+			if note.CreatedAt.After(follower.CursorCreatedAt) ||
+				(note.CreatedAt.Equal(follower.CursorCreatedAt) && note.Identifier > follower.CursorIdentifier) {
+				follower.CursorCreatedAt, follower.CursorIdentifier = note.CreatedAt, note.Identifier
+			}
+		}
+
+		if err := database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error; err != nil {
+				return err
+			}
+
+			// Other hubs may have moved the cursor further meanwhile
+			return tx.Model(&model.ActivityPubFollower{}).
+				Where("instance = ? AND actor = ?", follower.Instance, follower.Actor).
+				Where("(cursor_created_at, cursor_identifier) < (?, ?)", follower.CursorCreatedAt, follower.CursorIdentifier).
+				Updates(map[string]any{
+					"cursor_created_at": follower.CursorCreatedAt,
+					"cursor_identifier": follower.CursorIdentifier,
+				}).Error
+		}); err != nil {
+			return err
+		}
+
+		if backlog = len(notes) == enqueueBatchLimit; !backlog {
+			return nil
+		}
+	}
+}
+
+// EnqueueActivity queues an activity replying to a follower, e.g. an Accept, it is sent right away.
+func EnqueueActivity(instance string, inbox string, activity activitypub.Activity) error {
+	payload, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+
+	return database.DB.Create(&model.ActivityPubDelivery{
+		Instance:      instance,
+		Inbox:         inbox,
+		Activity:      payload,
+		Status:        webhook.StatusPending,
+		NextAttemptAt: time.Now(),
+	}).Error
+}
+
+// DeliverDue claims and sends the pending deliveries whose next attempt is due.
+func DeliverDue(ctx context.Context) error {
+	for {
+		deliveries := make([]model.ActivityPubDelivery, 0, deliverBatchLimit)
+
+		if err := database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.
+				Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("status = ? AND next_attempt_at <= ?", webhook.StatusPending, time.Now()).
+				Order("next_attempt_at").
+				Limit(deliverBatchLimit).
+				Find(&deliveries).Error; err != nil {
+				return err
+			}
+
+			if len(deliveries) == 0 {
+				return nil
+			}
+
+			ids := make([]int64, 0, len(deliveries))
+			for _, delivery := range deliveries {
+				ids = append(ids, delivery.ID)
+			}
+
+			return tx.Model(&model.ActivityPubDelivery{}).
+				Where("id IN ?", ids).
+				Update("next_attempt_at", time.Now().Add(deliveryLease)).Error
+		}); err != nil {
+			return err
+		}
+
+		var waitGroup sync.WaitGroup
+
+		for i := range deliveries {
+			waitGroup.Add(1)
+
+			go func(delivery *model.ActivityPubDelivery) {
+				defer waitGroup.Done()
+
+				if err := Deliver(ctx, delivery); err != nil {
+					logger.Errorf("deliver activitypub delivery %d: %v", delivery.ID, err)
+				}
+			}(&deliveries[i])
+		}
+
+		waitGroup.Wait()
+
+		if len(deliveries) < deliverBatchLimit {
+			return nil
+		}
+	}
+}
+
+// Deliver signs and sends a delivery to its inbox as the actor of the activity, and records the result.
+// Failed deliveries are retried with the backoff of webhooks. The returned error is only about recording,
+// the send error is kept in the delivery.
+func Deliver(ctx context.Context, delivery *model.ActivityPubDelivery) error {
+	key, err := PrivateKey()
+	if err != nil {
+		return err
+	}
+
+	activity := activitypub.Activity{}
+	if err := json.Unmarshal(delivery.Activity, &activity); err != nil {
+		return err
+	}
+
+	statusCode, err := activitypub.Deliver(ctx, client, delivery.Inbox, activitypub.KeyID(activity.Actor), key, delivery.Activity)
+
+	delivery.Attempts++
+	delivery.ResponseStatusCode = statusCode
+	delivery.Error = ""
+
+	switch {
+	case err == nil:
+		delivery.Status = webhook.StatusSucceeded
+	case delivery.Attempts >= webhook.MaxAttempts:
+		delivery.Status = webhook.StatusFailed
+		delivery.Error = err.Error()
+	default:
+		delivery.Status = webhook.StatusPending
+		delivery.Error = err.Error()
+		delivery.NextAttemptAt = time.Now().Add(webhook.Backoff(delivery.Attempts))
+	}
+
+	return database.DB.Select("*").Updates(delivery).Error
+}
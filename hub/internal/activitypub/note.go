@@ -0,0 +1,106 @@
+package activitypub
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol"
+)
+
+// NewCreateActivity wraps an item as a Note created by the actor,
+// objectID is the dereferenceable url of the item.
+func NewCreateActivity(actorID string, objectID string, item protocol.Item) Activity {
+	note := NewNote(actorID, objectID, item)
+
+	return Activity{
+		ID:        fmt.Sprintf("%s/activity", objectID),
+		Type:      TypeCreate,
+		Actor:     actorID,
+		Published: note.Published,
+		To:        note.To,
+		Object:    note,
+	}
+}
+
+func NewNote(actorID string, objectID string, item protocol.Item) Note {
+	note := Note{
+		ID:           objectID,
+		Type:         TypeNote,
+		AttributedTo: actorID,
+		Name:         item.Title,
+		Content:      content(item),
+		Published:    item.DateCreated.Time().UTC().Format(time.RFC3339),
+		To:           []string{Public},
+		Tag:          make([]Tag, 0, len(item.Tags)),
+		Attachment:   make([]Attachment, 0, len(item.Attachments)),
+	}
+
+	if !item.DateUpdated.Time().Equal(item.DateCreated.Time()) {
+		note.Updated = item.DateUpdated.Time().UTC().Format(time.RFC3339)
+	}
+
+	if len(item.RelatedURLs) > 0 {
+		note.URL = item.RelatedURLs[0]
+	}
+
+	for _, tag := range item.Tags {
+		note.Tag = append(note.Tag, Tag{
+			Type: TypeHashtag,
+			Name: fmt.Sprintf("#%s", tag),
+		})
+	}
+
+	for _, attachment := range item.Attachments {
+		// Attachments without an address are inline content, e.g. descriptions and dates
+		if attachment.Address == "" {
+			continue
+		}
+
+		note.Attachment = append(note.Attachment, Attachment{
+			Type:      attachmentType(attachment.MimeType),
+			MediaType: attachment.MimeType,
+			URL:       attachment.Address,
+			Name:      attachment.Type,
+		})
+	}
+
+	return note
+}
+
+func content(item protocol.Item) string {
+	paragraphs := make([]string, 0, 3)
+
+	if item.Title != "" {
+		paragraphs = append(paragraphs, fmt.Sprintf("<p><strong>%s</strong></p>", html.EscapeString(item.Title)))
+	}
+
+	if item.Summary != "" {
+		paragraphs = append(paragraphs, fmt.Sprintf("<p>%s</p>", html.EscapeString(item.Summary)))
+	}
+
+	if len(paragraphs) == 0 {
+		paragraphs = append(paragraphs, fmt.Sprintf("<p>%s</p>", html.EscapeString(item.Source)))
+	}
+
+	for _, relatedURL := range item.RelatedURLs {
+		escapedURL := html.EscapeString(relatedURL)
+		paragraphs = append(paragraphs, fmt.Sprintf(`<p><a href="%s">%s</a></p>`, escapedURL, escapedURL))
+	}
+
+	return strings.Join(paragraphs, "")
+}
+
+func attachmentType(mimeType string) string {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return TypeImage
+	case strings.HasPrefix(mimeType, "video/"):
+		return TypeVideo
+	case strings.HasPrefix(mimeType, "audio/"):
+		return TypeAudio
+	default:
+		return TypeDocument
+	}
+}
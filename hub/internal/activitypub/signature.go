@@ -0,0 +1,197 @@
+package activitypub
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SignatureHeaders are the headers covered by outgoing signatures.
+var SignatureHeaders = []string{"(request-target)", "host", "date", "digest"}
+
+var (
+	ErrInvalidPrivateKey = errors.New("invalid private key")
+	ErrInvalidPublicKey  = errors.New("invalid public key")
+	ErrInvalidSignature  = errors.New("invalid signature")
+)
+
+func ParsePrivateKey(privateKeyPem string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privateKeyPem))
+	if block == nil {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	if privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return privateKey, nil
+	}
+
+	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaPrivateKey, ok := privateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	return rsaPrivateKey, nil
+}
+
+func ParsePublicKey(publicKeyPem string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKeyPem))
+	if block == nil {
+		return nil, ErrInvalidPublicKey
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaPublicKey, ok := publicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, ErrInvalidPublicKey
+	}
+
+	return rsaPublicKey, nil
+}
+
+func EncodePublicKey(publicKey *rsa.PublicKey) (string, error) {
+	data, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: data})), nil
+}
+
+func Digest(body []byte) string {
+	sum := sha256.Sum256(body)
+
+	return fmt.Sprintf("SHA-256=%s", base64.StdEncoding.EncodeToString(sum[:]))
+}
+
+// Sign adds the Digest and Signature headers of draft-cavage-http-signatures,
+// the Date header must already be set.
+func Sign(request *http.Request, body []byte, keyID string, privateKey *rsa.PrivateKey) error {
+	request.Header.Set("Digest", Digest(body))
+
+	sum := sha256.Sum256([]byte(signingString(request, SignatureHeaders)))
+
+	signature, err := rsa.SignPKCS1v15(nil, privateKey, crypto.SHA256, sum[:])
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Signature", fmt.Sprintf(
+		`keyId="%s",algorithm="rsa-sha256",headers="%s",signature="%s"`,
+		keyID, strings.Join(SignatureHeaders, " "), base64.StdEncoding.EncodeToString(signature),
+	))
+
+	return nil
+}
+
+// SignatureKeyID returns the key id of a signed request without verifying it.
+func SignatureKeyID(request *http.Request) (string, error) {
+	parameters, err := parseSignature(request.Header.Get("Signature"))
+	if err != nil {
+		return "", err
+	}
+
+	return parameters["keyId"], nil
+}
+
+// Verify checks the signature and the digest of a request signed by Sign.
+func Verify(request *http.Request, body []byte, publicKey *rsa.PublicKey) error {
+	parameters, err := parseSignature(request.Header.Get("Signature"))
+	if err != nil {
+		return err
+	}
+
+	headers := strings.Fields(parameters["headers"])
+
+	// Every header signed by Sign must be covered, otherwise the body, the target or the date could be replaced
+	for _, header := range SignatureHeaders {
+		if !contains(headers, header) {
+			return ErrInvalidSignature
+		}
+	}
+
+	if request.Header.Get("Digest") != Digest(body) {
+		return ErrInvalidSignature
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(parameters["signature"])
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	sum := sha256.Sum256([]byte(signingString(request, headers)))
+
+	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, sum[:], signature); err != nil {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func signingString(request *http.Request, headers []string) string {
+	lines := make([]string, 0, len(headers))
+
+	for _, header := range headers {
+		var value string
+
+		switch header {
+		case "(request-target)":
+			value = fmt.Sprintf("%s %s", strings.ToLower(request.Method), request.URL.RequestURI())
+		case "host":
+			value = request.Host
+			if value == "" {
+				value = request.URL.Host
+			}
+		default:
+			value = request.Header.Get(header)
+		}
+
+		lines = append(lines, fmt.Sprintf("%s: %s", header, value))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func parseSignature(header string) (map[string]string, error) {
+	parameters := map[string]string{}
+
+	for _, parameter := range strings.Split(header, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(parameter), "=")
+		if !found {
+			continue
+		}
+
+		parameters[key] = strings.Trim(value, `"`)
+	}
+
+	if parameters["keyId"] == "" || parameters["signature"] == "" {
+		return nil, ErrInvalidSignature
+	}
+
+	return parameters, nil
+}
+
+func contains(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+
+	return false
+}
@@ -16,9 +16,13 @@ var (
 	CodeNoMethod = 10002
 	CodeDatabase = 10003
 	CodeIndexer  = 10004
+	CodeSearch   = 10005
 
 	// Service error
-	CodeInvalidParams = 20001
+	CodeInvalidParams   = 20001
+	CodeNotFound        = 20002
+	CodeUnauthorized    = 20003
+	CodeTooManyRequests = 20004
 
 	// Base error
 	ErrorUnknown = errors.New("unknown")
@@ -28,9 +32,13 @@ var (
 	ErrorNoMethod = errors.New("no method")
 	ErrorDatabase = errors.New("database error")
 	ErrorIndexer  = errors.New("indexer error")
+	ErrorSearch   = errors.New("search error")
 
 	// Service error
-	ErrorInvalidParams = errors.New("invalid params")
+	ErrorInvalidParams   = errors.New("invalid params")
+	ErrorNotFound        = errors.New("not found")
+	ErrorUnauthorized    = errors.New("unauthorized")
+	ErrorTooManyRequests = errors.New("too many requests")
 )
 
 var (
@@ -41,8 +49,12 @@ var (
 		CodeNoMethod: ErrorNoMethod,
 		CodeDatabase: ErrorDatabase,
 		CodeIndexer:  ErrorIndexer,
+		CodeSearch:   ErrorSearch,
 
-		CodeInvalidParams: ErrorInvalidParams,
+		CodeInvalidParams:   ErrorInvalidParams,
+		CodeNotFound:        ErrorNotFound,
+		CodeUnauthorized:    ErrorUnauthorized,
+		CodeTooManyRequests: ErrorTooManyRequests,
 	}
 	codeMap = map[string]int{}
 )
@@ -0,0 +1,37 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	Header = "X-API-Key"
+
+	// Prefix makes keys recognizable, e.g. by secret scanners
+	Prefix = "rss3_"
+
+	ClassCrawl = "crawl"
+	ClassRead  = "read"
+)
+
+// Generate returns a new key and the hash to store, the key itself is only shown once.
+func Generate() (string, string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	key := Prefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	return key, Hash(key), nil
+}
+
+func Hash(key string) string {
+	hash := sha256.Sum256([]byte(strings.TrimSpace(key)))
+
+	return hex.EncodeToString(hash[:])
+}
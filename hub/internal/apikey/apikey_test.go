@@ -0,0 +1,24 @@
+package apikey_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/apikey"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	key, hash, err := apikey.Generate()
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(key, apikey.Prefix))
+	assert.Equal(t, hash, apikey.Hash(key))
+	assert.Equal(t, hash, apikey.Hash(" "+key+"\n"))
+
+	otherKey, otherHash, err := apikey.Generate()
+	assert.Nil(t, err)
+	assert.NotEqual(t, key, otherKey)
+	assert.NotEqual(t, hash, otherHash)
+}
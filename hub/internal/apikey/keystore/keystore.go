@@ -0,0 +1,183 @@
+package keystore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/apikey"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/cache"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// findCacheExpiration is also how long a revoked key keeps working on a running hub
+	findCacheExpiration = time.Minute
+
+	usageKeyPrefix  = "hub:apikey:usage"
+	usageExpiration = 90 * 24 * time.Hour
+	usageDateLayout = "2006-01-02"
+)
+
+var ErrInvalidKey = errors.New("invalid api key")
+
+var (
+	findCache      = map[string]findCacheEntry{}
+	findCacheMutex sync.Mutex
+)
+
+type findCacheEntry struct {
+	key       model.APIKey
+	expiresAt time.Time
+}
+
+// Usage counts the requests of a key on a day, including the rate limited ones.
+type Usage struct {
+	Date         string
+	Crawl        int64
+	CrawlLimited int64
+	Read         int64
+	ReadLimited  int64
+}
+
+// Create returns the new key along with its model, the key can't be recovered later.
+func Create(name string, crawlLimit, readLimit int) (string, *model.APIKey, error) {
+	key, hash, err := apikey.Generate()
+	if err != nil {
+		return "", nil, err
+	}
+
+	keyModel := model.APIKey{
+		ID:         uuid.New(),
+		Name:       name,
+		KeyHash:    hash,
+		CrawlLimit: crawlLimit,
+		ReadLimit:  readLimit,
+	}
+
+	if err := database.DB.Create(&keyModel).Error; err != nil {
+		return "", nil, err
+	}
+
+	return key, &keyModel, nil
+}
+
+func Revoke(id uuid.UUID) error {
+	result := database.DB.
+		Model(&model.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", sql.NullTime{Time: time.Now(), Valid: true})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: %s is not an active key", ErrInvalidKey, id)
+	}
+
+	return nil
+}
+
+func List() ([]model.APIKey, error) {
+	keys := make([]model.APIKey, 0)
+
+	if err := database.DB.Order("created_at").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// Find returns the active key, ErrInvalidKey if it is unknown or revoked.
+func Find(key string) (*model.APIKey, error) {
+	hash := apikey.Hash(key)
+
+	findCacheMutex.Lock()
+	entry, exists := findCache[hash]
+	findCacheMutex.Unlock()
+
+	if exists && time.Now().Before(entry.expiresAt) {
+		return &entry.key, nil
+	}
+
+	keyModel := model.APIKey{}
+	if err := database.DB.Where("key_hash = ? AND revoked_at IS NULL", hash).First(&keyModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidKey
+		}
+
+		return nil, err
+	}
+
+	// Unknown keys are not cached, so random keys can't grow the cache
+	findCacheMutex.Lock()
+	findCache[hash] = findCacheEntry{key: keyModel, expiresAt: time.Now().Add(findCacheExpiration)}
+	findCacheMutex.Unlock()
+
+	return &keyModel, nil
+}
+
+// CountUsage counts a request of the class on the current day.
+func CountUsage(ctx context.Context, id uuid.UUID, class string, limited bool) error {
+	key := usageKey(id, time.Now())
+	field := class
+
+	if limited {
+		field = fmt.Sprintf("%s_limited", class)
+	}
+
+	pipeline := cache.GetRedisClient().Pipeline()
+	pipeline.HIncrBy(ctx, key, field, 1)
+	pipeline.Expire(ctx, key, usageExpiration)
+
+	_, err := pipeline.Exec(ctx)
+
+	return err
+}
+
+// GetUsage returns the usage of the last days, the latest first.
+func GetUsage(ctx context.Context, id uuid.UUID, days int) ([]Usage, error) {
+	now := time.Now()
+	usageList := make([]Usage, 0, days)
+
+	for i := 0; i < days; i++ {
+		date := now.AddDate(0, 0, -i)
+
+		values, err := cache.GetRedisClient().HGetAll(ctx, usageKey(id, date)).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		usage := Usage{Date: date.UTC().Format(usageDateLayout)}
+
+		for field, target := range map[string]*int64{
+			apikey.ClassCrawl:              &usage.Crawl,
+			apikey.ClassCrawl + "_limited": &usage.CrawlLimited,
+			apikey.ClassRead:               &usage.Read,
+			apikey.ClassRead + "_limited":  &usage.ReadLimited,
+		} {
+			if values[field] == "" {
+				continue
+			}
+
+			if *target, err = strconv.ParseInt(values[field], 10, 64); err != nil {
+				return nil, err
+			}
+		}
+
+		usageList = append(usageList, usage)
+	}
+
+	return usageList, nil
+}
+
+func usageKey(id uuid.UUID, date time.Time) string {
+	return cache.ConstructKey(usageKeyPrefix, id.String(), date.UTC().Format(usageDateLayout))
+}
@@ -0,0 +1,64 @@
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// signatureSize truncates the HMAC, it only has to make cursors unforgeable
+const signatureSize = 16
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrLastIdentifier rejects the parameter replaced by cursors,
+// ignoring it would send old clients back to the first page forever
+var ErrLastIdentifier = fmt.Errorf("%w: last_identifier is replaced by cursor, follow identifier_next", ErrInvalidCursor)
+
+// Encode returns an opaque cursor holding the sort key of the last item of a page,
+// signed together with the kind so a cursor of one list can't be used on another.
+func Encode(secret []byte, kind string, key any) (string, error) {
+	payload, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+
+	return encodedPayload + "." + base64.RawURLEncoding.EncodeToString(sign(secret, kind, encodedPayload)), nil
+}
+
+// Decode verifies the cursor and unmarshals its sort key into key.
+func Decode(secret []byte, kind string, value string, key any) error {
+	encodedPayload, encodedSignature, found := strings.Cut(value, ".")
+	if !found {
+		return ErrInvalidCursor
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, sign(secret, kind, encodedPayload)) {
+		return ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+
+	if err := json.Unmarshal(payload, key); err != nil {
+		return ErrInvalidCursor
+	}
+
+	return nil
+}
+
+func sign(secret []byte, kind string, encodedPayload string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(kind + "." + encodedPayload))
+
+	return mac.Sum(nil)[:signatureSize]
+}
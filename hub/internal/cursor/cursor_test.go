@@ -0,0 +1,47 @@
+package cursor_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/cursor"
+	"github.com/stretchr/testify/assert"
+)
+
+type key struct {
+	DateCreated time.Time `json:"date_created"`
+	Identifier  string    `json:"identifier"`
+}
+
+func TestCursor(t *testing.T) {
+	t.Parallel()
+
+	secret := []byte("secret")
+	expected := key{
+		DateCreated: time.Date(2022, 2, 22, 2, 22, 22, 222222000, time.UTC),
+		Identifier:  "rss3://note:0x8ee3c8d3c2f3a5f7fb1ed31f1b1f2bb5ad1b8e54e1c1e0dfa6d2c7aeb4a94b5b-0@ethereum",
+	}
+
+	value, err := cursor.Encode(secret, "note", expected)
+	assert.Nil(t, err)
+
+	actual := key{}
+	assert.Nil(t, cursor.Decode(secret, "note", value, &actual))
+	assert.True(t, expected.DateCreated.Equal(actual.DateCreated))
+	assert.Equal(t, expected.Identifier, actual.Identifier)
+
+	// Cursors are bound to the secret and the kind
+	assert.ErrorIs(t, cursor.Decode([]byte("other"), "note", value, &actual), cursor.ErrInvalidCursor)
+	assert.ErrorIs(t, cursor.Decode(secret, "asset", value, &actual), cursor.ErrInvalidCursor)
+
+	// Tampered payloads are rejected
+	tampered, err := cursor.Encode(secret, "note", key{DateCreated: time.Now(), Identifier: expected.Identifier})
+	assert.Nil(t, err)
+
+	payload, _, _ := strings.Cut(tampered, ".")
+	_, signature, _ := strings.Cut(value, ".")
+	assert.ErrorIs(t, cursor.Decode(secret, "note", payload+"."+signature, &actual), cursor.ErrInvalidCursor)
+
+	assert.ErrorIs(t, cursor.Decode(secret, "note", "foobar", &actual), cursor.ErrInvalidCursor)
+}
@@ -0,0 +1,48 @@
+package dao
+
+import (
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/cursor"
+	m "github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+)
+
+// BatchGetAssetList query data through database,
+// the total is the number of assets after the cursor
+func BatchGetAssetList(req m.BatchGetNodeListRequest) ([]model.Asset, int64, error) {
+	internalDB, err := filterItems(database.DB, req)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if len(req.Cursor) > 0 {
+		lastItem := assetCursor{}
+		if err := cursor.Decode(getCursorSecret(), cursorKindAsset, req.Cursor, &lastItem); err != nil {
+			return nil, 0, err
+		}
+
+		internalDB = internalDB.Where(
+			"(date_created, contract_address, token_id, identifier) < (?, ?, ?, ?)",
+			lastItem.DateCreated, lastItem.ContractAddress, lastItem.TokenID, lastItem.Identifier,
+		)
+	}
+
+	internalDB = internalDB.
+		Where("owner IN ?", getOwnerList(req.InstanceList)).
+		Order("date_created DESC").
+		Order("contract_address DESC").
+		Order("token_id DESC").
+		Order("identifier DESC")
+
+	var count int64
+	if err := internalDB.Model(&model.Asset{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	assetList := make([]model.Asset, 0)
+	if err := internalDB.Limit(req.Limit).Find(&assetList).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return assetList, count, nil
+}
@@ -0,0 +1,117 @@
+package dao
+
+import (
+	"crypto/rand"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/cursor"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/config"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
+)
+
+const (
+	cursorKindNote  = "note"
+	cursorKindAsset = "asset"
+	// Links and backlinks are sorted by a different column
+	cursorKindLink     = "link"
+	cursorKindBacklink = "backlink"
+)
+
+var ErrCursorSecretNotConfigured = errors.New("hub.cursor_secret is not configured")
+
+var (
+	cursorSecret     []byte
+	cursorSecretOnce sync.Once
+)
+
+// noteCursor is the sort key of notes, in the order of the columns
+type noteCursor struct {
+	DateCreated         time.Time `json:"date_created"`
+	TransactionHash     string    `json:"transaction_hash"`
+	TransactionLogIndex int       `json:"transaction_log_index"`
+	Identifier          string    `json:"identifier"`
+}
+
+// assetCursor is the sort key of assets, in the order of the columns
+type assetCursor struct {
+	DateCreated     time.Time `json:"date_created"`
+	ContractAddress string    `json:"contract_address"`
+	TokenID         string    `json:"token_id"`
+	Identifier      string    `json:"identifier"`
+}
+
+// linkCursor is the sort key of links, other is the target of links or the origin of backlinks
+type linkCursor struct {
+	DateCreated time.Time `json:"date_created"`
+	Other       string    `json:"other"`
+	Type        int       `json:"type"`
+	Source      int       `json:"source"`
+}
+
+// NoteCursor returns the cursor of the page after the note.
+func NoteCursor(note model.Note) (string, error) {
+	return cursor.Encode(getCursorSecret(), cursorKindNote, noteCursor{
+		DateCreated:         note.DateCreated,
+		TransactionHash:     note.TransactionHash,
+		TransactionLogIndex: note.TransactionLogIndex,
+		Identifier:          note.Identifier,
+	})
+}
+
+// AssetCursor returns the cursor of the page after the asset.
+func AssetCursor(asset model.Asset) (string, error) {
+	return cursor.Encode(getCursorSecret(), cursorKindAsset, assetCursor{
+		DateCreated:     asset.DateCreated,
+		ContractAddress: asset.ContractAddress,
+		TokenID:         asset.TokenID,
+		Identifier:      asset.Identifier,
+	})
+}
+
+// LinkCursor returns the cursor of the page after the link.
+func LinkCursor(link model.Link, backlink bool) (string, error) {
+	kind, other := cursorKindLink, link.Target
+	if backlink {
+		kind, other = cursorKindBacklink, link.Origin
+	}
+
+	return cursor.Encode(getCursorSecret(), kind, linkCursor{
+		DateCreated: link.DateCreated,
+		Other:       other,
+		Type:        link.Type,
+		Source:      link.Source,
+	})
+}
+
+// CheckCursorSecret requires the cursor secret outside of debug mode,
+// so that the cursors issued by a hub are accepted by the others and after restarts.
+func CheckCursorSecret() error {
+	if config.Config.Hub.CursorSecret == "" && config.Config.Hub.Server.RunMode != "debug" {
+		return ErrCursorSecretNotConfigured
+	}
+
+	return nil
+}
+
+// getCursorSecret falls back to a random secret in debug mode, cursors then only work on the instance that issued them
+func getCursorSecret() []byte {
+	cursorSecretOnce.Do(func() {
+		if config.Config.Hub.CursorSecret != "" {
+			cursorSecret = []byte(config.Config.Hub.CursorSecret)
+
+			return
+		}
+
+		logger.Warn("hub.cursor_secret is not set, using a random secret")
+
+		cursorSecret = make([]byte, 32)
+		if _, err := rand.Read(cursorSecret); err != nil {
+			panic(err)
+		}
+	})
+
+	return cursorSecret
+}
@@ -0,0 +1,87 @@
+package dao
+
+import (
+	"encoding/json"
+	"strings"
+
+	m "github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// getOwnerList returns the owners of the items of the instances
+func getOwnerList(instanceList []rss3uri.Instance) []string {
+	ownerList := make([]string, 0, len(instanceList))
+
+	for _, instance := range instanceList {
+		ownerList = append(ownerList, strings.ToLower(rss3uri.New(instance).String()))
+	}
+
+	return ownerList
+}
+
+// filterOwners keeps the items of the instances, or of the accounts followed by FollowedBy
+func filterOwners(internalDB *gorm.DB, req m.BatchGetNodeListRequest) *gorm.DB {
+	if req.FollowedBy != "" {
+		return internalDB.Where("owner IN (?)", database.DB.
+			Model(&model.Link{}).
+			Select("target").
+			Where("origin = ? AND type = ?", req.FollowedBy, constants.LinkTypeFollow.Int()),
+		)
+	}
+
+	return internalDB.Where("owner IN ?", getOwnerList(req.InstanceList))
+}
+
+// filterItems applies the filters shared by notes and assets
+func filterItems(internalDB *gorm.DB, req m.BatchGetNodeListRequest) (*gorm.DB, error) {
+	if req.Tags != nil && len(req.Tags) != 0 {
+		internalDB = internalDB.Where("tags && ?", pq.StringArray(req.Tags))
+	}
+
+	if req.ExcludeTags != nil && len(req.ExcludeTags) != 0 {
+		internalDB = internalDB.Where("tags && ? = FALSE", pq.StringArray(req.ExcludeTags))
+	}
+
+	if req.ItemSources != nil && len(req.ItemSources) != 0 {
+		internalDB = internalDB.Where("source IN ?", req.ItemSources)
+	}
+
+	if req.Networks != nil && len(req.Networks) != 0 {
+		internalDB = internalDB.Where("metadata_network IN ?", req.Networks)
+	}
+
+	// Containment keeps the GIN index of attachments usable
+	if len(req.MimeTypes) != 0 {
+		mimeTypeDB := database.DB
+
+		for i, mimeType := range req.MimeTypes {
+			attachments, err := json.Marshal([]map[string]string{{"mime_type": mimeType}})
+			if err != nil {
+				return nil, err
+			}
+
+			if i == 0 {
+				mimeTypeDB = mimeTypeDB.Where("attachments @> ?::jsonb", string(attachments))
+			} else {
+				mimeTypeDB = mimeTypeDB.Or("attachments @> ?::jsonb", string(attachments))
+			}
+		}
+
+		internalDB = internalDB.Where(mimeTypeDB)
+	}
+
+	if !req.DateFrom.IsZero() {
+		internalDB = internalDB.Where("date_created >= ?", req.DateFrom)
+	}
+
+	if !req.DateTo.IsZero() {
+		internalDB = internalDB.Where("date_created < ?", req.DateTo)
+	}
+
+	return internalDB, nil
+}
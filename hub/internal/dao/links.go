@@ -0,0 +1,59 @@
+package dao
+
+import (
+	"fmt"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/cursor"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+)
+
+// GetLinkList queries the links starting from the owner, or pointing to it for backlinks,
+// the total is the number of links after the cursor
+func GetLinkList(owner string, types []int, sources []int, backlink bool, cursorValue string, limit int) ([]model.Link, int64, error) {
+	// Backlinks are the same links viewed from the target side
+	selfColumn, otherColumn, kind := "origin", "target", cursorKindLink
+	if backlink {
+		selfColumn, otherColumn, kind = "target", "origin", cursorKindBacklink
+	}
+
+	internalDB := database.DB.Where(fmt.Sprintf("%s = ?", selfColumn), owner)
+
+	if len(types) != 0 {
+		internalDB = internalDB.Where("type IN ?", types)
+	}
+
+	if len(sources) != 0 {
+		internalDB = internalDB.Where("source IN ?", sources)
+	}
+
+	if len(cursorValue) > 0 {
+		lastItem := linkCursor{}
+		if err := cursor.Decode(getCursorSecret(), kind, cursorValue, &lastItem); err != nil {
+			return nil, 0, err
+		}
+
+		internalDB = internalDB.Where(
+			fmt.Sprintf("(date_created, %s, type, source) < (?, ?, ?, ?)", otherColumn),
+			lastItem.DateCreated, lastItem.Other, lastItem.Type, lastItem.Source,
+		)
+	}
+
+	internalDB = internalDB.
+		Order("date_created DESC").
+		Order(fmt.Sprintf("%s DESC", otherColumn)).
+		Order("type DESC").
+		Order("source DESC")
+
+	var count int64
+	if err := internalDB.Model(&model.Link{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	linkList := make([]model.Link, 0)
+	if err := internalDB.Limit(limit).Find(&linkList).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return linkList, count, nil
+}
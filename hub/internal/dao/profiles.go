@@ -0,0 +1,68 @@
+package dao
+
+import (
+	"strings"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
+)
+
+// GetProfileList query the profiles of an account and the accounts connected with it,
+// lastProfile only needs the primary key of the last profile of the previous page
+func GetProfileList(
+	instance rss3uri.Instance, limit int, lastProfile *model.Profile, profileSourceIDs []int,
+) ([]model.Profile, int64, error) {
+	identity := strings.ToLower(instance.GetIdentity())
+	platform := constants.PlatformSymbol(instance.GetSuffix()).ID().Int()
+
+	// Profiles belong to the account itself or to the accounts connected with it
+	internalDB := database.DB.Where(
+		"(id = ? AND platform = ?) OR (id, platform) IN (?)",
+		identity, platform,
+		database.DB.
+			Model(&model.Account{}).
+			Select("profile_id", "profile_platform").
+			Where("identity = ? AND platform = ?", identity, platform),
+	)
+
+	if lastProfile != nil {
+		var lastItem model.Profile
+		if err := database.DB.
+			Where("id = ? AND platform = ? AND source = ?", lastProfile.ID, lastProfile.Platform, lastProfile.Source).
+			First(&lastItem).Error; err != nil {
+			return nil, 0, err
+		}
+
+		internalDB = internalDB.Where(
+			"(created_at, source, platform, id) < (?, ?, ?, ?)",
+			lastItem.CreatedAt, lastItem.Source, lastItem.Platform, lastItem.ID,
+		)
+	}
+
+	if len(profileSourceIDs) != 0 {
+		internalDB = internalDB.Where("source IN ?", profileSourceIDs)
+	}
+
+	profiles := make([]model.Profile, 0)
+	if err := internalDB.
+		Limit(limit).
+		Order("created_at DESC").
+		Order("source DESC").
+		Order("platform DESC").
+		Order("id DESC").
+		Find(&profiles).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var count int64
+
+	if err := internalDB.
+		Model(&model.Profile{}).
+		Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return profiles, count, nil
+}
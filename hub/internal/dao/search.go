@@ -0,0 +1,36 @@
+package dao
+
+import (
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+)
+
+// GetNoteListByIdentifiers returns the notes in no particular order, missing ones are left out.
+func GetNoteListByIdentifiers(identifiers []string) ([]model.Note, error) {
+	noteList := make([]model.Note, 0, len(identifiers))
+
+	if len(identifiers) == 0 {
+		return noteList, nil
+	}
+
+	if err := database.DB.Where("identifier IN ?", identifiers).Find(&noteList).Error; err != nil {
+		return nil, err
+	}
+
+	return noteList, nil
+}
+
+// GetAssetListByIdentifiers returns the assets in no particular order, missing ones are left out.
+func GetAssetListByIdentifiers(identifiers []string) ([]model.Asset, error) {
+	assetList := make([]model.Asset, 0, len(identifiers))
+
+	if len(identifiers) == 0 {
+		return assetList, nil
+	}
+
+	if err := database.DB.Where("identifier IN ?", identifiers).Find(&assetList).Error; err != nil {
+		return nil, err
+	}
+
+	return assetList, nil
+}
@@ -0,0 +1,100 @@
+package dao
+
+import (
+	"time"
+
+	m "github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+	"gorm.io/gorm"
+)
+
+// ItemStats counts the items matching a request by group, months are formatted as YYYY-MM in UTC.
+type ItemStats struct {
+	Total     int64            `json:"total"`
+	DateFirst *time.Time       `json:"date_first"`
+	DateLast  *time.Time       `json:"date_last"`
+	Tags      map[string]int64 `json:"tags"`
+	Networks  map[string]int64 `json:"networks"`
+	Sources   map[string]int64 `json:"sources"`
+	Months    map[string]int64 `json:"months"`
+}
+
+type groupCount struct {
+	Name  string
+	Count int64
+}
+
+// GetNoteStats ignores the limit and cursor of the request.
+func GetNoteStats(req m.BatchGetNodeListRequest) (*ItemStats, error) {
+	return getItemStats(&model.Note{}, req)
+}
+
+// GetAssetStats ignores the limit and cursor of the request.
+func GetAssetStats(req m.BatchGetNodeListRequest) (*ItemStats, error) {
+	return getItemStats(&model.Asset{}, req)
+}
+
+func getItemStats(itemModel any, req m.BatchGetNodeListRequest) (*ItemStats, error) {
+	internalDB, err := filterItems(database.DB.Model(itemModel), req)
+	if err != nil {
+		return nil, err
+	}
+
+	// A new session lets every query below start from the filters
+	internalDB = internalDB.Where("owner IN ?", getOwnerList(req.InstanceList)).Session(&gorm.Session{})
+
+	summary := struct {
+		Total     int64
+		DateFirst *time.Time
+		DateLast  *time.Time
+	}{}
+
+	if err := internalDB.
+		Select("COUNT(*) AS total, MIN(date_created) AS date_first, MAX(date_created) AS date_last").
+		Scan(&summary).Error; err != nil {
+		return nil, err
+	}
+
+	stats := ItemStats{
+		Total:     summary.Total,
+		DateFirst: summary.DateFirst,
+		DateLast:  summary.DateLast,
+	}
+
+	for _, group := range []struct {
+		counts *map[string]int64
+		db     *gorm.DB
+		name   string
+	}{
+		{&stats.Tags, internalDB.Joins("CROSS JOIN UNNEST(tags) AS tag"), "tag"},
+		{&stats.Networks, internalDB, "metadata_network"},
+		{&stats.Sources, internalDB, "source"},
+		{&stats.Months, internalDB, "TO_CHAR(date_created AT TIME ZONE 'UTC', 'YYYY-MM')"},
+	} {
+		if *group.counts, err = countGroup(group.db, group.name); err != nil {
+			return nil, err
+		}
+	}
+
+	return &stats, nil
+}
+
+func countGroup(internalDB *gorm.DB, name string) (map[string]int64, error) {
+	groupCountList := make([]groupCount, 0)
+
+	if err := internalDB.
+		Select(name + " AS name, COUNT(*) AS count").
+		Group(name).
+		Scan(&groupCountList).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(groupCountList))
+
+	for _, groupCount := range groupCountList {
+		counts[groupCount.Name] = groupCount.Count
+	}
+
+	return counts, nil
+}
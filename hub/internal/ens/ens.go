@@ -0,0 +1,213 @@
+package ens
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/cache"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/config"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	goens "github.com/wealdtech/go-ens/v3"
+	"gorm.io/gorm"
+)
+
+const (
+	Suffix = ".eth"
+
+	cacheKeyPrefix = "ens"
+
+	// Names change hands rarely, unknown names are kept shorter as they might be registered soon
+	cacheExpiration         = time.Hour
+	cacheNotFoundExpiration = 10 * time.Minute
+
+	rpcTimeout = 5 * time.Second
+)
+
+var (
+	ErrNotFound = errors.New("ens name not found")
+
+	errNoGateway = errors.New("gateway endpoint is not configured")
+
+	// notFoundErrors are what go-ens returns for names without an address, other errors are failed calls
+	notFoundErrors = []string{"unregistered name", "no resolver", "no address", "bad name"}
+
+	clientMutex sync.Mutex
+	client      *ethclient.Client
+)
+
+// IsName tells whether the identity of an account is an ENS name instead of an address.
+func IsName(identity string) bool {
+	return strings.HasSuffix(identity, Suffix) && !common.IsHexAddress(identity)
+}
+
+// Resolver looks up the address of a normalized name, both lookups return ErrNotFound for unknown names.
+type Resolver struct {
+	Domains  func(ctx context.Context, name string) (string, error)
+	Registry func(ctx context.Context, name string) (string, error)
+}
+
+// DefaultResolver looks into the domains filled by the ENS subscriber and the registry through the gateway.
+var DefaultResolver = Resolver{
+	Domains:  resolveByDomains,
+	Registry: resolveByRegistry,
+}
+
+// Resolve returns the lowercase address of the name with the DefaultResolver.
+func Resolve(ctx context.Context, name string) (string, error) {
+	return DefaultResolver.Resolve(ctx, name)
+}
+
+// Resolve returns the lowercase address of the name, looking into the cache, then the domains
+// and then the registry for the names the domains miss or if they are out of reach.
+// Failed lookups are not cached, unknown names are cached for a shorter time.
+func (r Resolver) Resolve(ctx context.Context, name string) (string, error) {
+	name, err := goens.NormaliseDomain(name)
+	if err != nil {
+		return "", ErrNotFound
+	}
+
+	cacheKey := cache.ConstructKey(cacheKeyPrefix, name)
+
+	address, err := cache.GetRaw(ctx, cacheKey)
+	if err == nil {
+		if address == "" {
+			return "", ErrNotFound
+		}
+
+		return address, nil
+	}
+
+	if !errors.Is(err, cache.CacheMissedError) {
+		logger.Errorf("get ens cache: %v", err)
+	}
+
+	address, err = r.Domains(ctx, name)
+	if err != nil {
+		domainsErr := err
+		if !errors.Is(domainsErr, ErrNotFound) {
+			logger.Warnf("resolve ens name %s by domains: %v", name, domainsErr)
+		}
+
+		address, err = r.Registry(ctx, name)
+		if errors.Is(err, errNoGateway) {
+			err = domainsErr
+		}
+	}
+
+	expiration := cacheExpiration
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		address = ""
+		expiration = cacheNotFoundExpiration
+	case err != nil:
+		return "", err
+	}
+
+	if err := cache.SetRaw(ctx, cacheKey, address, expiration); err != nil {
+		logger.Errorf("set ens cache: %v", err)
+	}
+
+	if address == "" {
+		return "", ErrNotFound
+	}
+
+	return address, nil
+}
+
+// resolveByDomains only knows the second level names, which are what the subscriber records
+func resolveByDomains(ctx context.Context, name string) (string, error) {
+	if goens.DomainLevel(name) != 1 {
+		return "", ErrNotFound
+	}
+
+	domain := model.Domains{}
+
+	if err := database.DB.
+		WithContext(ctx).
+		Where("type = ? AND name = ? AND expired_at > ?", "ens", strings.TrimSuffix(name, Suffix), time.Now()).
+		Order("block_timestamp DESC").
+		First(&domain).
+		Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrNotFound
+		}
+
+		return "", err
+	}
+
+	// The target is the resolved address, the owner is the registrant which is the same unless it was changed
+	address := domain.AddressTarget
+	if len(address) != common.AddressLength {
+		address = domain.AddressOwner
+	}
+
+	if len(address) != common.AddressLength {
+		return "", ErrNotFound
+	}
+
+	return strings.ToLower(common.BytesToAddress(address).String()), nil
+}
+
+func resolveByRegistry(ctx context.Context, name string) (string, error) {
+	ethClient, err := getClient(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	address, err := goens.Resolve(ethClient, name)
+	if err != nil {
+		for _, notFoundErr := range notFoundErrors {
+			if err.Error() == notFoundErr {
+				return "", ErrNotFound
+			}
+		}
+
+		return "", err
+	}
+
+	return strings.ToLower(address.String()), nil
+}
+
+func getClient(ctx context.Context) (*ethclient.Client, error) {
+	clientMutex.Lock()
+	defer clientMutex.Unlock()
+
+	if client != nil {
+		return client, nil
+	}
+
+	endpoint := config.Config.Indexer.Gateway.Endpoint
+	if endpoint == "" {
+		return nil, errNoGateway
+	}
+
+	var (
+		rpcClient *rpc.Client
+		err       error
+	)
+
+	// go-ens calls without a context, the timeout of the http client bounds them instead
+	if strings.HasPrefix(endpoint, "http") {
+		rpcClient, err = rpc.DialHTTPWithClient(endpoint, &http.Client{Timeout: rpcTimeout})
+	} else {
+		rpcClient, err = rpc.DialContext(ctx, endpoint)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	client = ethclient.NewClient(rpcClient)
+
+	return client, nil
+}
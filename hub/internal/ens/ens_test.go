@@ -0,0 +1,91 @@
+package ens_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/ens"
+	"github.com/stretchr/testify/assert"
+)
+
+const address = "0xc8b960d09c0078c18dcbe7eb9ab9d816bcca8944"
+
+var errLookup = errors.New("lookup failed")
+
+func lookup(address string, err error) func(ctx context.Context, name string) (string, error) {
+	return func(ctx context.Context, name string) (string, error) {
+		return address, err
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		resolver ens.Resolver
+		address  string
+		err      error
+		// cached tells whether the answer is kept, so that the next lookups are not called
+		cached bool
+	}{
+		{
+			name:     "domains",
+			resolver: ens.Resolver{Domains: lookup(address, nil), Registry: lookup("", errLookup)},
+			address:  address,
+			cached:   true,
+		},
+		{
+			name:     "registry on domains miss",
+			resolver: ens.Resolver{Domains: lookup("", ens.ErrNotFound), Registry: lookup(address, nil)},
+			address:  address,
+			cached:   true,
+		},
+		{
+			name:     "registry on domains error",
+			resolver: ens.Resolver{Domains: lookup("", errLookup), Registry: lookup(address, nil)},
+			address:  address,
+			cached:   true,
+		},
+		{
+			name:     "miss",
+			resolver: ens.Resolver{Domains: lookup("", ens.ErrNotFound), Registry: lookup("", ens.ErrNotFound)},
+			err:      ens.ErrNotFound,
+			cached:   true,
+		},
+		{
+			name:     "registry error",
+			resolver: ens.Resolver{Domains: lookup("", ens.ErrNotFound), Registry: lookup("", errLookup)},
+			err:      errLookup,
+			cached:   false,
+		},
+	}
+
+	for i, testcase := range testcases {
+		testcase := testcase
+		name := fmt.Sprintf("resolve-test-%d-%d.eth", time.Now().UnixNano(), i)
+
+		t.Run(testcase.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := testcase.resolver.Resolve(context.Background(), name)
+			assert.ErrorIs(t, err, testcase.err)
+			assert.Equal(t, testcase.address, result)
+
+			// The second lookup is answered by the cache, or by the lookups again
+			second := ens.Resolver{Domains: lookup("", ens.ErrNotFound), Registry: lookup(address, nil)}
+
+			result, err = second.Resolve(context.Background(), name)
+			if testcase.cached {
+				assert.ErrorIs(t, err, testcase.err)
+				assert.Equal(t, testcase.address, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, address, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package feed
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol"
+	"github.com/gorilla/feeds"
+)
+
+const (
+	FormatRSS  = "rss"
+	FormatAtom = "atom"
+	FormatJSON = "json"
+)
+
+var contentTypeMap = map[string]string{
+	FormatRSS:  "application/rss+xml; charset=utf-8",
+	FormatAtom: "application/atom+xml; charset=utf-8",
+	FormatJSON: "application/feed+json; charset=utf-8",
+}
+
+// ContentType returns the MIME type of the feed format.
+func ContentType(format string) string {
+	return contentTypeMap[format]
+}
+
+// Feed renders a list of protocol items as RSS 2.0, Atom or JSON Feed.
+type Feed struct {
+	Title       string
+	Link        string
+	NextLink    string
+	Description string
+	Updated     time.Time
+	Items       []protocol.Item
+}
+
+func (f *Feed) Render(format string) (string, error) {
+	switch format {
+	case FormatRSS:
+		return f.RSS()
+	case FormatAtom:
+		return f.Atom()
+	case FormatJSON:
+		return f.JSON()
+	default:
+		return "", fmt.Errorf("unsupported feed format %s", format)
+	}
+}
+
+// RSS only keeps the first enclosure of each item, as RSS 2.0 allows a single enclosure per item.
+func (f *Feed) RSS() (string, error) {
+	return f.feed().ToRss()
+}
+
+func (f *Feed) Atom() (string, error) {
+	atomFeed := (&feeds.Atom{Feed: f.feed()}).AtomFeed()
+
+	for i, entry := range atomFeed.Entries {
+		item := f.Items[i]
+
+		// The first related url is already the alternate link of the entry
+		for j, relatedURL := range item.RelatedURLs {
+			if j == 0 {
+				continue
+			}
+
+			entry.Links = append(entry.Links, feeds.AtomLink{Href: relatedURL, Rel: "related"})
+		}
+
+		for j, enclosure := range enclosures(item) {
+			// The first enclosure is already added by gorilla/feeds
+			if j == 0 {
+				continue
+			}
+
+			entry.Links = append(entry.Links, feeds.AtomLink{
+				Href:   enclosure.Url,
+				Rel:    "enclosure",
+				Type:   enclosure.Type,
+				Length: enclosure.Length,
+			})
+		}
+	}
+
+	return feeds.ToXML(atomFeed)
+}
+
+func (f *Feed) JSON() (string, error) {
+	jsonFeed := (&feeds.JSON{Feed: f.feed()}).JSONFeed()
+	jsonFeed.FeedUrl = f.Link
+	jsonFeed.NextUrl = f.NextLink
+
+	for i, jsonItem := range jsonFeed.Items {
+		item := f.Items[i]
+
+		if len(item.RelatedURLs) > 1 {
+			jsonItem.ExternalUrl = item.RelatedURLs[1]
+		}
+
+		jsonItem.Tags = item.Tags
+
+		for _, enclosure := range enclosures(item) {
+			size, _ := strconv.ParseInt(enclosure.Length, 10, 32)
+
+			jsonItem.Attachments = append(jsonItem.Attachments, feeds.JSONAttachment{
+				Url:      enclosure.Url,
+				MIMEType: enclosure.Type,
+				Size:     int32(size),
+			})
+		}
+	}
+
+	return jsonFeed.ToJSON()
+}
+
+func (f *Feed) feed() *feeds.Feed {
+	feed := &feeds.Feed{
+		Title:       f.Title,
+		Link:        &feeds.Link{Href: f.Link},
+		Description: f.Description,
+		Id:          f.Link,
+		Updated:     f.Updated,
+		Items:       make([]*feeds.Item, 0, len(f.Items)),
+	}
+
+	for _, item := range f.Items {
+		feedItem := &feeds.Item{
+			Title:       title(item),
+			Description: item.Summary,
+			Id:          item.Identifier,
+			Created:     item.DateCreated.Time(),
+			Updated:     item.DateUpdated.Time(),
+			// Items without related urls link to themselves
+			Link: &feeds.Link{Href: item.Identifier},
+		}
+
+		if len(item.RelatedURLs) > 0 {
+			feedItem.Link.Href = item.RelatedURLs[0]
+		}
+
+		if len(item.Authors) > 0 {
+			feedItem.Author = &feeds.Author{Name: item.Authors[0]}
+		}
+
+		if itemEnclosures := enclosures(item); len(itemEnclosures) > 0 {
+			feedItem.Enclosure = itemEnclosures[0]
+		}
+
+		feed.Add(feedItem)
+	}
+
+	return feed
+}
+
+// Falls back to the summary or the source since feed readers expect every entry to have a title
+func title(item protocol.Item) string {
+	if item.Title != "" {
+		return item.Title
+	}
+
+	if item.Summary != "" {
+		if summary := []rune(item.Summary); len(summary) > 50 {
+			return string(summary[:50]) + "..."
+		}
+
+		return item.Summary
+	}
+
+	return item.Source
+}
+
+// Only attachments with an address can be linked as enclosures
+func enclosures(item protocol.Item) []*feeds.Enclosure {
+	result := make([]*feeds.Enclosure, 0)
+
+	for _, attachment := range item.Attachments {
+		if attachment.Address == "" || strings.HasPrefix(attachment.Address, "data:") {
+			continue
+		}
+
+		result = append(result, &feeds.Enclosure{
+			Url:    attachment.Address,
+			Type:   attachment.MimeType,
+			Length: strconv.FormatInt(attachment.SizeInBytes, 10),
+		})
+	}
+
+	return result
+}
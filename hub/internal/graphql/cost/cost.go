@@ -0,0 +1,161 @@
+package cost
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Analyzer estimates the number of objects a query may resolve before it runs,
+// every field costs one and the fields of a connection are multiplied by its page size.
+type Analyzer struct {
+	// Connections are the names of the fields paginated by FirstArgument
+	Connections   map[string]struct{}
+	FirstArgument string
+	DefaultFirst  int
+	MaxFirst      int
+}
+
+// Cost returns the cost of the operation, the document must have been validated.
+func (a Analyzer) Cost(document *ast.Document, operationName string, variables map[string]any) (int, error) {
+	var operation *ast.OperationDefinition
+
+	fragments := map[string]*ast.FragmentDefinition{}
+
+	for _, definition := range document.Definitions {
+		switch definition := definition.(type) {
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operation = definition
+			}
+		case *ast.FragmentDefinition:
+			fragments[definition.Name.Value] = definition
+		}
+	}
+
+	if operation == nil {
+		return 0, fmt.Errorf("unknown operation %s", operationName)
+	}
+
+	// Variables left out by the request fall back to their default values
+	values := map[string]any{}
+
+	for _, definition := range operation.VariableDefinitions {
+		if intValue, ok := definition.DefaultValue.(*ast.IntValue); ok {
+			if defaultValue, err := strconv.Atoi(intValue.Value); err == nil {
+				values[definition.Variable.Name.Value] = defaultValue
+			}
+		}
+	}
+
+	for name, value := range variables {
+		values[name] = value
+	}
+
+	return a.selectionSetCost(operation.SelectionSet, fragments, values, map[string]struct{}{})
+}
+
+func (a Analyzer) selectionSetCost(
+	selectionSet *ast.SelectionSet, fragments map[string]*ast.FragmentDefinition, variables map[string]any, visiting map[string]struct{},
+) (int, error) {
+	if selectionSet == nil {
+		return 0, nil
+	}
+
+	total := 0
+
+	for _, selection := range selectionSet.Selections {
+		var (
+			cost int
+			err  error
+		)
+
+		switch selection := selection.(type) {
+		case *ast.Field:
+			cost, err = a.fieldCost(selection, fragments, variables, visiting)
+		case *ast.InlineFragment:
+			cost, err = a.selectionSetCost(selection.SelectionSet, fragments, variables, visiting)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+
+			fragment, exists := fragments[name]
+			if !exists {
+				return 0, fmt.Errorf("unknown fragment %s", name)
+			}
+
+			if _, cyclic := visiting[name]; cyclic {
+				return 0, fmt.Errorf("cyclic fragment %s", name)
+			}
+
+			visiting[name] = struct{}{}
+			cost, err = a.selectionSetCost(fragment.SelectionSet, fragments, variables, visiting)
+			delete(visiting, name)
+		}
+
+		if err != nil {
+			return 0, err
+		}
+
+		total += cost
+	}
+
+	return total, nil
+}
+
+func (a Analyzer) fieldCost(
+	field *ast.Field, fragments map[string]*ast.FragmentDefinition, variables map[string]any, visiting map[string]struct{},
+) (int, error) {
+	childrenCost, err := a.selectionSetCost(field.SelectionSet, fragments, variables, visiting)
+	if err != nil {
+		return 0, err
+	}
+
+	multiplier := 1
+
+	if _, isConnection := a.Connections[field.Name.Value]; isConnection {
+		if multiplier, err = a.first(field, variables); err != nil {
+			return 0, err
+		}
+	}
+
+	return 1 + multiplier*childrenCost, nil
+}
+
+// first returns the page size of a connection, capped by MaxFirst since larger values are rejected anyway.
+func (a Analyzer) first(field *ast.Field, variables map[string]any) (int, error) {
+	first := a.DefaultFirst
+
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != a.FirstArgument {
+			continue
+		}
+
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			parsedValue, err := strconv.Atoi(value.Value)
+			if err != nil {
+				return 0, err
+			}
+
+			first = parsedValue
+		case *ast.Variable:
+			switch variable := variables[value.Name.Value].(type) {
+			case int:
+				first = variable
+			case float64:
+				first = int(variable)
+			}
+		}
+	}
+
+	if first > a.MaxFirst {
+		return a.MaxFirst, nil
+	}
+
+	if first < 0 {
+		return 0, nil
+	}
+
+	return first, nil
+}
@@ -0,0 +1,88 @@
+package cost_test
+
+import (
+	"testing"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/graphql/cost"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+var analyzer = cost.Analyzer{
+	Connections:   map[string]struct{}{"notes": {}},
+	FirstArgument: "first",
+	DefaultFirst:  20,
+	MaxFirst:      100,
+}
+
+func TestCost(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		query     string
+		variables map[string]any
+		cost      int
+	}{
+		{
+			name:  "literal",
+			query: `{ account(instance: "x") { notes(first: 10) { totalCount edges { node { identifier } } } } }`,
+			cost:  1 + 1 + 10*(1+1+(1+1)),
+		},
+		{
+			name:  "default",
+			query: `{ account(instance: "x") { notes { totalCount edges { node { identifier } } } } }`,
+			cost:  1 + 1 + 20*(1+1+(1+1)),
+		},
+		{
+			name:  "capped",
+			query: `{ account(instance: "x") { notes(first: 1000) { totalCount edges { node { identifier } } } } }`,
+			cost:  1 + 1 + 100*(1+1+(1+1)),
+		},
+		{
+			name:  "variable default",
+			query: `query ($first: Int = 5) { account(instance: "x") { notes(first: $first) { totalCount edges { node { identifier } } } } }`,
+			cost:  1 + 1 + 5*(1+1+(1+1)),
+		},
+		{
+			name:      "variable",
+			query:     `query ($first: Int = 5) { account(instance: "x") { notes(first: $first) { totalCount edges { node { identifier } } } } }`,
+			variables: map[string]any{"first": float64(50)},
+			cost:      1 + 1 + 50*(1+1+(1+1)),
+		},
+		{
+			name: "fragment",
+			query: `{ account(instance: "x") { ...notes } }
+fragment notes on Account { notes(first: 10) { totalCount edges { node { identifier } } } }`,
+			cost: 1 + 1 + 10*(1+1+(1+1)),
+		},
+		{
+			name: "aliases",
+			query: `{
+  a: account(instance: "x") { notes(first: 100) { edges { node { identifier } } } }
+  b: account(instance: "y") { notes(first: 100) { edges { node { identifier } } } }
+}`,
+			cost: 2 * (1 + 1 + 100*(1+(1+1))),
+		},
+		{
+			name:  "nested",
+			query: `{ account(instance: "x") { notes(first: 10) { edges { node { notes(first: 10) { totalCount } } } } } }`,
+			cost:  1 + 1 + 10*(1+(1+(1+10*1))),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			document, err := parser.Parse(parser.ParseParams{Source: testCase.query})
+			assert.Nil(t, err)
+
+			queryCost, err := analyzer.Cost(document, "", testCase.variables)
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.cost, queryCost)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/graphql/cost"
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// MaxCost allows e.g. a hundred notes with their attachments for each of a few accounts
+const MaxCost = 5000
+
+var analyzer = cost.Analyzer{
+	Connections:   Connections,
+	FirstArgument: "first",
+	DefaultFirst:  DefaultFirst,
+	MaxFirst:      MaxFirst,
+}
+
+type Request struct {
+	Query         string         `json:"query" form:"query" binding:"required"`
+	OperationName string         `json:"operationName" form:"operationName"`
+	Variables     map[string]any `json:"variables" form:"-"`
+}
+
+// Do parses, validates and prices a request before executing it.
+func Do(ctx context.Context, request Request) *gql.Result {
+	document, err := parser.Parse(parser.ParseParams{Source: request.Query})
+	if err != nil {
+		return &gql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	if validationResult := gql.ValidateDocument(&Schema, document, nil); !validationResult.IsValid {
+		return &gql.Result{Errors: validationResult.Errors}
+	}
+
+	queryCost, err := analyzer.Cost(document, request.OperationName, request.Variables)
+	if err != nil {
+		return &gql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	if queryCost > MaxCost {
+		return &gql.Result{
+			Errors: gqlerrors.FormatErrors(fmt.Errorf("query cost %d exceeds the limit of %d", queryCost, MaxCost)),
+		}
+	}
+
+	result := gql.Execute(gql.ExecuteParams{
+		Schema:        Schema,
+		AST:           document,
+		OperationName: request.OperationName,
+		Args:          request.Variables,
+		Context:       ctx,
+	})
+
+	if result.Extensions == nil {
+		result.Extensions = map[string]any{}
+	}
+
+	result.Extensions["cost"] = queryCost
+
+	return result
+}
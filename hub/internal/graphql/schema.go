@@ -0,0 +1,502 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/dao"
+	m "github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/service"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/timex"
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultFirst = 20
+	MaxFirst     = 100
+)
+
+type connection struct {
+	TotalCount int64
+	Edges      []edge
+	PageInfo   pageInfo
+}
+
+type edge struct {
+	Cursor string
+	Node   any
+}
+
+type pageInfo struct {
+	HasNextPage bool
+	EndCursor   *string
+}
+
+var timeScalar = gql.NewScalar(gql.ScalarConfig{
+	Name:        "Time",
+	Description: "An ISO 8601 date time",
+	Serialize: func(value any) any {
+		switch value := value.(type) {
+		case timex.Time:
+			return value.Time().Format(timex.ISO8601)
+		case time.Time:
+			return value.Format(timex.ISO8601)
+		default:
+			return nil
+		}
+	},
+	ParseValue: func(value any) any {
+		if value, ok := value.(string); ok {
+			return parseTime(value)
+		}
+
+		return nil
+	},
+	ParseLiteral: func(valueAST ast.Value) any {
+		if valueAST, ok := valueAST.(*ast.StringValue); ok {
+			return parseTime(valueAST.Value)
+		}
+
+		return nil
+	},
+})
+
+var jsonScalar = gql.NewScalar(gql.ScalarConfig{
+	Name:        "JSON",
+	Description: "An arbitrary JSON value",
+	Serialize: func(value any) any {
+		return value
+	},
+	ParseValue: func(value any) any {
+		return value
+	},
+	ParseLiteral: func(valueAST ast.Value) any {
+		return nil
+	},
+})
+
+var pageInfoType = gql.NewObject(gql.ObjectConfig{
+	Name: "PageInfo",
+	Fields: gql.Fields{
+		"hasNextPage": &gql.Field{Type: gql.NewNonNull(gql.Boolean)},
+		"endCursor":   &gql.Field{Type: gql.String, Description: "Pass it as `after` to get the next page"},
+	},
+})
+
+var attachmentType = gql.NewObject(gql.ObjectConfig{
+	Name: "Attachment",
+	Fields: gql.Fields{
+		"type":        &gql.Field{Type: gql.String},
+		"content":     &gql.Field{Type: gql.String},
+		"address":     &gql.Field{Type: gql.String},
+		"mimeType":    &gql.Field{Type: gql.String},
+		"sizeInBytes": &gql.Field{Type: gql.Int},
+	},
+})
+
+var itemType = gql.NewObject(gql.ObjectConfig{
+	Name:        "Item",
+	Description: "A note or an asset",
+	Fields: gql.Fields{
+		"identifier":  &gql.Field{Type: gql.NewNonNull(gql.String)},
+		"dateCreated": &gql.Field{Type: timeScalar},
+		"dateUpdated": &gql.Field{Type: timeScalar},
+		"relatedURLs": &gql.Field{Type: gql.NewList(gql.String)},
+		"links":       &gql.Field{Type: gql.String},
+		"backlinks":   &gql.Field{Type: gql.String},
+		"tags":        &gql.Field{Type: gql.NewList(gql.String)},
+		"authors":     &gql.Field{Type: gql.NewList(gql.String)},
+		"title":       &gql.Field{Type: gql.String},
+		"summary":     &gql.Field{Type: gql.String},
+		"attachments": &gql.Field{Type: gql.NewList(attachmentType)},
+		"source":      &gql.Field{Type: gql.String},
+		"metadata":    &gql.Field{Type: jsonScalar},
+	},
+})
+
+var profileType = gql.NewObject(gql.ObjectConfig{
+	Name: "Profile",
+	Fields: gql.Fields{
+		"identifier":  &gql.Field{Type: gql.NewNonNull(gql.String)},
+		"dateCreated": &gql.Field{Type: timeScalar},
+		"dateUpdated": &gql.Field{Type: timeScalar},
+		"name":        &gql.Field{Type: gql.String},
+		"avatars":     &gql.Field{Type: gql.NewList(gql.String)},
+		"bio":         &gql.Field{Type: gql.String},
+		"attachments": &gql.Field{Type: gql.NewList(attachmentType)},
+		"source":      &gql.Field{Type: gql.String},
+		"metadata":    &gql.Field{Type: jsonScalar},
+	},
+})
+
+var (
+	itemConnectionType    = newConnectionType("Item", itemType)
+	profileConnectionType = newConnectionType("Profile", profileType)
+)
+
+var itemConnectionArgs = gql.FieldConfigArgument{
+	"first":       &gql.ArgumentConfig{Type: gql.Int, DefaultValue: DefaultFirst},
+	"after":       &gql.ArgumentConfig{Type: gql.String, Description: "The endCursor of the previous page"},
+	"tags":        &gql.ArgumentConfig{Type: gql.NewList(gql.NewNonNull(gql.String))},
+	"excludeTags": &gql.ArgumentConfig{Type: gql.NewList(gql.NewNonNull(gql.String))},
+	"itemSources": &gql.ArgumentConfig{Type: gql.NewList(gql.NewNonNull(gql.String))},
+	"networks":    &gql.ArgumentConfig{Type: gql.NewList(gql.NewNonNull(gql.String))},
+	"mimeTypes":   &gql.ArgumentConfig{Type: gql.NewList(gql.NewNonNull(gql.String))},
+	"dateFrom":    &gql.ArgumentConfig{Type: timeScalar, Description: "Inclusive"},
+	"dateTo":      &gql.ArgumentConfig{Type: timeScalar, Description: "Exclusive"},
+}
+
+var accountType = gql.NewObject(gql.ObjectConfig{
+	Name: "Account",
+	Fields: gql.Fields{
+		"instance": &gql.Field{
+			Type: gql.NewNonNull(gql.String),
+			Resolve: func(p gql.ResolveParams) (any, error) {
+				return p.Source.(*rss3uri.PlatformInstance).String(), nil
+			},
+		},
+		"identifier": &gql.Field{
+			Type: gql.NewNonNull(gql.String),
+			Resolve: func(p gql.ResolveParams) (any, error) {
+				return rss3uri.New(p.Source.(*rss3uri.PlatformInstance)).String(), nil
+			},
+		},
+		"profiles": &gql.Field{
+			Type: gql.NewNonNull(profileConnectionType),
+			Args: gql.FieldConfigArgument{
+				"first":          &gql.ArgumentConfig{Type: gql.Int, DefaultValue: DefaultFirst},
+				"after":          &gql.ArgumentConfig{Type: gql.String, Description: "The same as last_identifier"},
+				"profileSources": &gql.ArgumentConfig{Type: gql.NewList(gql.NewNonNull(gql.String))},
+			},
+			Resolve: resolveProfiles,
+		},
+		"notes": &gql.Field{
+			Type:    gql.NewNonNull(itemConnectionType),
+			Args:    itemConnectionArgs,
+			Resolve: resolveNotes,
+		},
+		"assets": &gql.Field{
+			Type:    gql.NewNonNull(itemConnectionType),
+			Args:    itemConnectionArgs,
+			Resolve: resolveAssets,
+		},
+	},
+})
+
+var queryType = gql.NewObject(gql.ObjectConfig{
+	Name: "Query",
+	Fields: gql.Fields{
+		"account": &gql.Field{
+			Type: accountType,
+			Args: gql.FieldConfigArgument{
+				"instance": &gql.ArgumentConfig{
+					Type:        gql.NewNonNull(gql.String),
+					Description: "e.g. account:0xc8b960d09c0078c18dcbe7eb9ab9d816bcca8944@ethereum",
+				},
+			},
+			Resolve: resolveAccount,
+		},
+		"note": &gql.Field{
+			Type:    itemType,
+			Args:    gql.FieldConfigArgument{"identifier": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)}},
+			Resolve: resolveNote,
+		},
+		"asset": &gql.Field{
+			Type:    itemType,
+			Args:    gql.FieldConfigArgument{"identifier": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)}},
+			Resolve: resolveAsset,
+		},
+	},
+})
+
+// Connections are the fields paginated by `first`, used by the cost analyzer
+var Connections = map[string]struct{}{
+	"profiles": {},
+	"notes":    {},
+	"assets":   {},
+}
+
+var Schema = func() gql.Schema {
+	schema, err := gql.NewSchema(gql.SchemaConfig{Query: queryType})
+	if err != nil {
+		panic(err)
+	}
+
+	return schema
+}()
+
+func newConnectionType(name string, nodeType *gql.Object) *gql.Object {
+	edgeType := gql.NewObject(gql.ObjectConfig{
+		Name: fmt.Sprintf("%sEdge", name),
+		Fields: gql.Fields{
+			"cursor": &gql.Field{Type: gql.NewNonNull(gql.String)},
+			"node":   &gql.Field{Type: gql.NewNonNull(nodeType)},
+		},
+	})
+
+	return gql.NewObject(gql.ObjectConfig{
+		Name: fmt.Sprintf("%sConnection", name),
+		Fields: gql.Fields{
+			"totalCount": &gql.Field{Type: gql.NewNonNull(gql.Int)},
+			"edges":      &gql.Field{Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(edgeType)))},
+			"pageInfo":   &gql.Field{Type: gql.NewNonNull(pageInfoType)},
+		},
+	})
+}
+
+func resolveAccount(p gql.ResolveParams) (any, error) {
+	instance, err := rss3uri.ParseInstance(p.Args["instance"].(string))
+	if err != nil {
+		return nil, err
+	}
+
+	platformInstance, ok := instance.(*rss3uri.PlatformInstance)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an account instance", instance)
+	}
+
+	return platformInstance, nil
+}
+
+func resolveNote(p gql.ResolveParams) (any, error) {
+	note := model.Note{}
+	if err := database.DB.Where("identifier = ?", strings.ToLower(p.Args["identifier"].(string))).First(&note).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	itemList, _, _, err := service.FormatProtocolItemByNote([]model.Note{note})
+	if err != nil {
+		return nil, err
+	}
+
+	return itemList[0], nil
+}
+
+func resolveAsset(p gql.ResolveParams) (any, error) {
+	asset := model.Asset{}
+	if err := database.DB.Where("identifier = ?", strings.ToLower(p.Args["identifier"].(string))).First(&asset).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	itemList, _, _, err := service.FormatProtocolItemByAsset([]model.Asset{asset})
+	if err != nil {
+		return nil, err
+	}
+
+	return itemList[0], nil
+}
+
+func resolveNotes(p gql.ResolveParams) (any, error) {
+	request, err := newBatchGetNodeListRequest(p)
+	if err != nil {
+		return nil, err
+	}
+
+	noteList, total, err := dao.BatchGetNodeList(request)
+	if err != nil {
+		return nil, err
+	}
+
+	itemList, _, _, err := service.FormatProtocolItemByNote(noteList)
+	if err != nil {
+		return nil, err
+	}
+
+	cursors := make([]string, 0, len(noteList))
+
+	for _, note := range noteList {
+		noteCursor, err := dao.NoteCursor(note)
+		if err != nil {
+			return nil, err
+		}
+
+		cursors = append(cursors, noteCursor)
+	}
+
+	return newItemConnection(itemList, cursors, total, request.Limit), nil
+}
+
+func resolveAssets(p gql.ResolveParams) (any, error) {
+	request, err := newBatchGetNodeListRequest(p)
+	if err != nil {
+		return nil, err
+	}
+
+	assetList, total, err := dao.BatchGetAssetList(request)
+	if err != nil {
+		return nil, err
+	}
+
+	itemList, _, _, err := service.FormatProtocolItemByAsset(assetList)
+	if err != nil {
+		return nil, err
+	}
+
+	cursors := make([]string, 0, len(assetList))
+
+	for _, asset := range assetList {
+		assetCursor, err := dao.AssetCursor(asset)
+		if err != nil {
+			return nil, err
+		}
+
+		cursors = append(cursors, assetCursor)
+	}
+
+	return newItemConnection(itemList, cursors, total, request.Limit), nil
+}
+
+func resolveProfiles(p gql.ResolveParams) (any, error) {
+	first, err := getFirst(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastProfile *model.Profile
+
+	if after, ok := p.Args["after"].(string); ok && after != "" {
+		if lastProfile, err = service.ParseProfileIdentifier(after); err != nil {
+			return nil, err
+		}
+	}
+
+	profileSourceIDs := make([]int, 0)
+
+	for _, profileSource := range getStringList(p, "profileSources") {
+		profileSourceID := constants.ProfileSourceName(profileSource).ID()
+		if profileSourceID == constants.ProfileSourceIDUnknown {
+			return nil, fmt.Errorf("invalid profile source %s", profileSource)
+		}
+
+		profileSourceIDs = append(profileSourceIDs, profileSourceID.Int())
+	}
+
+	profileModels, total, err := dao.GetProfileList(p.Source.(*rss3uri.PlatformInstance), first, lastProfile, profileSourceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	profileList, _, _, err := service.FormatProtocolProfile(profileModels)
+	if err != nil {
+		return nil, err
+	}
+
+	result := connection{
+		TotalCount: total,
+		Edges:      make([]edge, 0, len(profileList)),
+	}
+
+	for _, profile := range profileList {
+		result.Edges = append(result.Edges, edge{Cursor: profile.Identifier, Node: profile})
+	}
+
+	result.PageInfo = newPageInfo(result.Edges, total, first)
+
+	return result, nil
+}
+
+func newBatchGetNodeListRequest(p gql.ResolveParams) (m.BatchGetNodeListRequest, error) {
+	first, err := getFirst(p)
+	if err != nil {
+		return m.BatchGetNodeListRequest{}, err
+	}
+
+	after, _ := p.Args["after"].(string)
+	dateFrom, _ := p.Args["dateFrom"].(time.Time)
+	dateTo, _ := p.Args["dateTo"].(time.Time)
+
+	return m.BatchGetNodeListRequest{
+		Limit:        first,
+		Cursor:       after,
+		Tags:         getStringList(p, "tags"),
+		ExcludeTags:  getStringList(p, "excludeTags"),
+		ItemSources:  getStringList(p, "itemSources"),
+		Networks:     getStringList(p, "networks"),
+		MimeTypes:    getStringList(p, "mimeTypes"),
+		DateFrom:     dateFrom,
+		DateTo:       dateTo,
+		InstanceList: []rss3uri.Instance{p.Source.(*rss3uri.PlatformInstance)},
+	}, nil
+}
+
+func newItemConnection(itemList []protocol.Item, cursors []string, total int64, first int) connection {
+	result := connection{
+		TotalCount: total,
+		Edges:      make([]edge, 0, len(itemList)),
+	}
+
+	for i, item := range itemList {
+		result.Edges = append(result.Edges, edge{Cursor: cursors[i], Node: item})
+	}
+
+	result.PageInfo = newPageInfo(result.Edges, total, first)
+
+	return result
+}
+
+// newPageInfo relies on total counting the items after the cursor
+func newPageInfo(edges []edge, total int64, first int) pageInfo {
+	result := pageInfo{
+		HasNextPage: total > int64(first),
+	}
+
+	if len(edges) > 0 {
+		result.EndCursor = &edges[len(edges)-1].Cursor
+	}
+
+	return result
+}
+
+func getFirst(p gql.ResolveParams) (int, error) {
+	first, ok := p.Args["first"].(int)
+	if !ok {
+		return DefaultFirst, nil
+	}
+
+	if first < 1 || first > MaxFirst {
+		return 0, fmt.Errorf("first must be between 1 and %d", MaxFirst)
+	}
+
+	return first, nil
+}
+
+func getStringList(p gql.ResolveParams, name string) []string {
+	values, _ := p.Args[name].([]any)
+
+	result := make([]string, 0, len(values))
+
+	for _, value := range values {
+		if value, ok := value.(string); ok {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
+
+// parseTime returns nil for invalid values so they are reported as invalid arguments
+func parseTime(value string) any {
+	result, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+
+	return result
+}
@@ -0,0 +1,453 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/activitypub"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/activitypub/federation"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/api"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/middleware"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/netguard"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/service"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/config"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	TracerNameGetOutbox = "get_outbox"
+
+	// activityPubMaxClockSkew bounds the Date header of signed requests to limit replays
+	activityPubMaxClockSkew = 12 * time.Hour
+	// activityPubMaxActorSize bounds the documents fetched from the actors of signed requests
+	activityPubMaxActorSize = 1 << 20
+	// activityPubMaxInboxSize bounds the activities posted to the inboxes, which are read before being verified
+	activityPubMaxInboxSize = 1 << 20
+)
+
+var (
+	ErrActivityPubInvalidActor = errors.New("invalid actor")
+	ErrActivityPubInvalidDate  = errors.New("invalid date")
+
+	// activityPubActorClient fetches the keys of unauthenticated requests, so it gives up early
+	activityPubActorClient = netguard.NewClient(5*time.Second, false)
+)
+
+type GetWebFingerRequest struct {
+	Resource string `form:"resource" binding:"required"`
+}
+
+type inboxActivity struct {
+	ID     string          `json:"id"`
+	Type   string          `json:"type"`
+	Actor  string          `json:"actor"`
+	Object json.RawMessage `json:"object"`
+}
+
+type remoteActor struct {
+	ID        string                `json:"id"`
+	Inbox     string                `json:"inbox"`
+	PublicKey activitypub.PublicKey `json:"publicKey"`
+}
+
+// GetWebFingerHandlerFunc resolves acct:<identity>.<platform>@<domain> to the actor of an account instance.
+func GetWebFingerHandlerFunc(c *gin.Context) {
+	request := GetWebFingerRequest{}
+	if err := c.ShouldBindQuery(&request); err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	instance, err := parseWebFingerResource(c, request.Resource)
+	if err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	actorURL := getActorURL(c, instance)
+
+	c.Header("Content-Type", activitypub.JRDContentType)
+	c.JSON(http.StatusOK, activitypub.WebFinger{
+		Subject: request.Resource,
+		Aliases: []string{actorURL},
+		Links: []activitypub.WebFingerLink{
+			{
+				Rel:  "self",
+				Type: activitypub.ContentType,
+				Href: actorURL,
+			},
+		},
+	})
+}
+
+func GetActorHandlerFunc(c *gin.Context) {
+	instance, err := middleware.GetPlatformInstance(c)
+	if err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	actorURL := getActorURL(c, instance)
+
+	actor := activitypub.Actor{
+		Context:           []string{activitypub.ContextActivityStreams, activitypub.ContextSecurity},
+		ID:                actorURL,
+		Type:              activitypub.TypePerson,
+		PreferredUsername: getWebFingerUsername(instance),
+		Name:              instance.String(),
+		Inbox:             fmt.Sprintf("%s/inbox", strings.TrimSuffix(actorURL, "/actor")),
+		Outbox:            fmt.Sprintf("%s/outbox", strings.TrimSuffix(actorURL, "/actor")),
+	}
+
+	profileModels, _, err := getProfileListByInstance(instance, GetProfileListRequest{Limit: 1}, nil)
+	if err != nil {
+		api.SetError(c, api.ErrorDatabase, err)
+
+		return
+	}
+
+	profileList, _, errType, err := service.FormatProtocolProfile(profileModels)
+	if err != nil {
+		api.SetError(c, errType, err)
+
+		return
+	}
+
+	if len(profileList) > 0 {
+		profile := profileList[0]
+
+		if profile.Name != "" {
+			actor.Name = profile.Name
+		}
+
+		actor.Summary = profile.Bio
+
+		if len(profile.Avatars) > 0 {
+			actor.Icon = &activitypub.Image{
+				Type: activitypub.TypeImage,
+				URL:  profile.Avatars[0],
+			}
+		}
+	}
+
+	// Actors without a public key can still be browsed but not be followed
+	if privateKey, err := federation.PrivateKey(); err == nil {
+		publicKeyPem, err := activitypub.EncodePublicKey(&privateKey.PublicKey)
+		if err != nil {
+			api.SetError(c, api.ErrorUnknown, err)
+
+			return
+		}
+
+		actor.PublicKey = &activitypub.PublicKey{
+			ID:           activitypub.KeyID(actorURL),
+			Owner:        actorURL,
+			PublicKeyPem: publicKeyPem,
+		}
+	}
+
+	c.Header("Content-Type", activitypub.ContentType)
+	c.JSON(http.StatusOK, actor)
+}
+
+// GetOutboxHandlerFunc returns the notes of an instance as Create activities,
+// accepting the same query parameters as GetNoteListHandlerFunc.
+func GetOutboxHandlerFunc(c *gin.Context) {
+	tracer := otel.Tracer(TracerNameGetOutbox)
+
+	ctx, httpSnap := tracer.Start(c.Request.Context(), "http_handler")
+
+	defer httpSnap.End()
+
+	instance, err := middleware.GetPlatformInstance(c)
+	if err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	file, noteList, errType, err := getNoteListFile(ctx, c, "outbox")
+	if err != nil {
+		httpSnap.RecordError(err)
+		httpSnap.SetStatus(codes.Error, err.Error())
+
+		api.SetError(c, errType, err)
+
+		return
+	}
+
+	actorURL := getActorURL(c, instance)
+	outboxURL := fmt.Sprintf("%s/outbox", strings.TrimSuffix(actorURL, "/actor"))
+
+	if notModified(c, file, getOwner(instance)) {
+		return
+	}
+
+	c.Header("Content-Type", activitypub.ContentType)
+
+	if c.Query("page") == "" {
+		c.JSON(http.StatusOK, activitypub.OrderedCollection{
+			Context:    activitypub.ContextActivityStreams,
+			ID:         outboxURL,
+			Type:       activitypub.TypeOrderedCollection,
+			TotalItems: file.Total,
+			First:      fmt.Sprintf("%s?page=true", outboxURL),
+		})
+
+		return
+	}
+
+	page := activitypub.OrderedCollectionPage{
+		Context:      activitypub.ContextActivityStreams,
+		ID:           fmt.Sprintf("%s?%s", outboxURL, c.Request.URL.RawQuery),
+		Type:         activitypub.TypeOrderedCollectionPage,
+		PartOf:       outboxURL,
+		OrderedItems: make([]activitypub.Activity, 0, len(noteList)),
+	}
+
+	if _, nextQuery, found := strings.Cut(file.IdentifierNext, "?"); found {
+		page.Next = fmt.Sprintf("%s?%s", outboxURL, nextQuery)
+	}
+
+	for _, note := range noteList {
+		page.OrderedItems = append(page.OrderedItems, activitypub.NewCreateActivity(actorURL, getObjectURL(c, note.Identifier), note))
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// PostInboxHandlerFunc accepts signed Follow and Undo activities from fediverse servers,
+// other activities are acknowledged and dropped.
+func PostInboxHandlerFunc(c *gin.Context) {
+	instance, err := middleware.GetPlatformInstance(c)
+	if err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	if _, err := federation.PrivateKey(); err != nil {
+		api.SetError(c, api.ErrorUnknown, err)
+
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, activityPubMaxInboxSize))
+	if err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	activity := inboxActivity{}
+	if err := json.Unmarshal(body, &activity); err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	actor, err := verifyInboxRequest(c, body, activity)
+	if err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	actorURL := getActorURL(c, instance)
+
+	switch activity.Type {
+	case activitypub.TypeFollow:
+		var object string
+		if err := json.Unmarshal(activity.Object, &object); err != nil || object != actorURL {
+			api.SetError(c, api.ErrorInvalidParams, ErrActivityPubInvalidActor)
+
+			return
+		}
+
+		// The notes created from now on are delivered to the follower
+		if err := database.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&model.ActivityPubFollower{
+			Instance:        instance.String(),
+			Actor:           actor.ID,
+			Inbox:           actor.Inbox,
+			CursorCreatedAt: time.Now(),
+		}).Error; err != nil {
+			api.SetError(c, api.ErrorDatabase, err)
+
+			return
+		}
+
+		accept := activitypub.Activity{
+			Context: activitypub.ContextActivityStreams,
+			ID:      fmt.Sprintf("%s#accepts/%s", actorURL, url.QueryEscape(activity.ID)),
+			Type:    activitypub.TypeAccept,
+			Actor:   actorURL,
+			Object:  json.RawMessage(body),
+		}
+
+		if err := federation.EnqueueActivity(instance.String(), actor.Inbox, accept); err != nil {
+			api.SetError(c, api.ErrorDatabase, err)
+
+			return
+		}
+	case activitypub.TypeUndo:
+		var object inboxActivity
+		if err := json.Unmarshal(activity.Object, &object); err != nil || object.Type != activitypub.TypeFollow {
+			break
+		}
+
+		if err := database.DB.
+			Where("instance = ? AND actor = ?", instance.String(), actor.ID).
+			Delete(&model.ActivityPubFollower{}).Error; err != nil {
+			api.SetError(c, api.ErrorDatabase, err)
+
+			return
+		}
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// verifyInboxRequest checks the http signature of a request against the key of its actor.
+func verifyInboxRequest(c *gin.Context, body []byte, activity inboxActivity) (*remoteActor, error) {
+	date, err := http.ParseTime(c.GetHeader("Date"))
+	if err != nil || time.Since(date) > activityPubMaxClockSkew || time.Until(date) > activityPubMaxClockSkew {
+		return nil, ErrActivityPubInvalidDate
+	}
+
+	keyID, err := activitypub.SignatureKeyID(c.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	actorURL, _, _ := strings.Cut(keyID, "#")
+
+	actor, err := fetchRemoteActor(c.Request.Context(), actorURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if actor.ID != activity.Actor || actor.PublicKey.ID != keyID {
+		return nil, ErrActivityPubInvalidActor
+	}
+
+	// Activities are delivered to the inbox later on
+	if _, err := netguard.CheckURL(c.Request.Context(), actor.Inbox, false, "https"); err != nil {
+		return nil, err
+	}
+
+	publicKey, err := activitypub.ParsePublicKey(actor.PublicKey.PublicKeyPem)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := activitypub.Verify(c.Request, body, publicKey); err != nil {
+		return nil, err
+	}
+
+	return actor, nil
+}
+
+// fetchRemoteActor fetches the actor of a key id given by an unauthenticated request,
+// only https actors on public addresses are fetched.
+func fetchRemoteActor(ctx context.Context, actorURL string) (*remoteActor, error) {
+	if _, err := netguard.CheckURL(ctx, actorURL, false, "https"); err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, actorURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Accept", activitypub.ContentType)
+
+	response, err := activityPubActorClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch actor %s: %s", actorURL, response.Status)
+	}
+
+	actor := remoteActor{}
+	if err := json.NewDecoder(io.LimitReader(response.Body, activityPubMaxActorSize)).Decode(&actor); err != nil {
+		return nil, err
+	}
+
+	return &actor, nil
+}
+
+// getBaseURL returns the configured activitypub domain or the host of the current request.
+func getBaseURL(c *gin.Context) string {
+	if domain := config.Config.Hub.ActivityPub.Domain; domain != "" {
+		return fmt.Sprintf("https://%s", domain)
+	}
+
+	return strings.TrimSuffix(getRequestURL(c, ""), c.Request.URL.Path)
+}
+
+func getActorURL(c *gin.Context, instance *rss3uri.PlatformInstance) string {
+	return activitypub.ActorURL(getBaseURL(c), instance)
+}
+
+// getObjectURL converts an rss3 identifier to the url resolving it on the hub.
+func getObjectURL(c *gin.Context, identifier string) string {
+	return activitypub.ObjectURL(getBaseURL(c), identifier)
+}
+
+// getWebFingerUsername joins the identity and the platform with a dot,
+// identities may contain dots themselves so the platform is split at the last one.
+func getWebFingerUsername(instance *rss3uri.PlatformInstance) string {
+	return fmt.Sprintf("%s.%s", instance.Identity, instance.Platform)
+}
+
+func parseWebFingerResource(c *gin.Context, resource string) (*rss3uri.PlatformInstance, error) {
+	account := strings.TrimPrefix(resource, "acct:")
+
+	separator := strings.LastIndex(account, "@")
+	if separator < 0 {
+		return nil, rss3uri.ErrInvalidIdentity
+	}
+
+	username, domain := account[:separator], account[separator+1:]
+	if !strings.EqualFold(domain, strings.TrimPrefix(strings.TrimPrefix(getBaseURL(c), "https://"), "http://")) {
+		return nil, fmt.Errorf("unknown domain %s", domain)
+	}
+
+	separator = strings.LastIndex(username, ".")
+	if separator < 0 {
+		return nil, rss3uri.ErrInvalidIdentity
+	}
+
+	instance, err := rss3uri.NewInstance(string(constants.PrefixNameAccount), username[:separator], username[separator+1:])
+	if err != nil {
+		return nil, err
+	}
+
+	platformInstance, ok := instance.(*rss3uri.PlatformInstance)
+	if !ok {
+		return nil, rss3uri.ErrInvalidIdentity
+	}
+
+	return platformInstance, nil
+}
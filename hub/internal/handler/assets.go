@@ -1,35 +1,40 @@
 package handler
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"strings"
+	"time"
 
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/api"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/cursor"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/dao"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/indexer"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/middleware"
+	m "github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/model"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol"
-	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/serializer"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/service"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
-	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/timex"
 	"github.com/gin-gonic/gin"
-	"github.com/lib/pq"
 )
 
 type GetAssetListRequest struct {
-	Limit          int      `form:"limit"`
-	LastIdentifier string   `form:"last_identifier"`
-	Tags           []string `form:"tags"`
-	ExcludeTags    []string `form:"exclude_tags"`
-	MimeTypes      []string `form:"mime_types"`
-	ItemSources    []string `form:"item_sources"`
-	Networks       []string `form:"networks"`
-	Latest         bool     `form:"latest"`
+	Limit       int       `form:"limit"`
+	Cursor      string    `form:"cursor"`
+	Tags        []string  `form:"tags"`
+	ExcludeTags []string  `form:"exclude_tags"`
+	MimeTypes   []string  `form:"mime_types"`
+	ItemSources []string  `form:"item_sources"`
+	Networks    []string  `form:"networks"`
+	DateFrom    time.Time `form:"date_from"`
+	DateTo      time.Time `form:"date_to"`
+	Latest      bool      `form:"latest"`
+
+	LastIdentifier string `form:"last_identifier"` // replaced by Cursor, only bound to be rejected
 }
 
-// nolint:funlen // TODO
 func GetAssetListHandlerFunc(c *gin.Context) {
 	instance, err := middleware.GetPlatformInstance(c)
 	if err != nil {
@@ -45,151 +50,111 @@ func GetAssetListHandlerFunc(c *gin.Context) {
 		return
 	}
 
-	assetModels, total, err := getAssetListByInstance(c, instance, request)
-
-	if err != nil {
-		api.SetError(c, api.ErrorDatabase, err)
+	if request.LastIdentifier != "" {
+		api.SetError(c, api.ErrorInvalidParams, cursor.ErrLastIdentifier)
 
 		return
 	}
 
-	uri := rss3uri.New(instance)
-
-	var dateUpdated *timex.Time
-
-	assetList := make([]protocol.Item, len(assetModels))
-
-	for i, assetModel := range assetModels {
-		attachmentList := make([]protocol.ItemAttachment, 0)
-		if err = json.Unmarshal(assetModel.Attachments, &attachmentList); err != nil {
-			api.SetError(c, api.ErrorIndexer, err)
+	assetModels, total, err := getAssetListByInstance(c, instance, request)
+	if err != nil {
+		if errors.Is(err, cursor.ErrInvalidCursor) {
+			api.SetError(c, api.ErrorInvalidParams, err)
 
 			return
 		}
 
-		internalTime := timex.Time(assetModel.DateUpdated)
-		if dateUpdated == nil {
-			dateUpdated = &internalTime
-		} else if dateUpdated.Time().Before(assetModel.DateUpdated) {
-			dateUpdated = &internalTime
-		}
-
-		// Build metadata
-		metadata := make(map[string]interface{})
-
-		if err := json.Unmarshal(assetModel.Metadata, &metadata); err != nil {
-			api.SetError(c, api.ErrorIndexer, err)
-
-			return
-		}
+		api.SetError(c, api.ErrorDatabase, err)
 
-		metadata["network"] = assetModel.MetadataNetwork
-		metadata["proof"] = assetModel.MetadataProof
-
-		assetList[i] = protocol.Item{
-			Identifier:  assetModel.Identifier,
-			DateCreated: timex.Time(assetModel.DateCreated),
-			DateUpdated: timex.Time(assetModel.DateUpdated),
-			RelatedURLs: assetModel.RelatedURLs,
-			Links:       fmt.Sprintf("%s/links", assetModel.Identifier),
-			BackLinks:   fmt.Sprintf("%s/backlinks", assetModel.Identifier),
-			Tags:        assetModel.Tags,
-			Authors:     assetModel.Authors,
-			Title:       assetModel.Title,
-			Summary:     assetModel.Summary,
-			Attachments: attachmentList,
-			Source:      assetModel.Source,
-			Metadata:    metadata,
-		}
+		return
 	}
 
-	var lastItem *protocol.Item
+	assetList, dateUpdated, errType, err := service.FormatProtocolItemByAsset(assetModels)
+	if err != nil {
+		api.SetError(c, errType, err)
 
-	if len(assetList) > 0 {
-		lastItem = &assetList[len(assetList)-1]
+		return
 	}
 
+	uri := rss3uri.New(instance)
+
 	identifierNext := ""
 
-	if len(assetList) == database.MaxLimit {
-		nextQuery := c.Request.URL.Query()
-		if lastItem != nil {
-			nextQuery.Set("last_identifier", lastItem.Identifier)
+	if total > int64(request.Limit) && len(assetModels) > 0 {
+		nextCursor, err := dao.AssetCursor(assetModels[len(assetModels)-1])
+		if err != nil {
+			api.SetError(c, api.ErrorUnknown, err)
+
+			return
 		}
 
+		nextQuery := c.Request.URL.Query()
+		nextQuery.Set("cursor", nextCursor)
+
 		identifierNext = fmt.Sprintf("%s/assets?%s", uri.String(), nextQuery.Encode())
 	}
 
-	c.JSON(http.StatusOK, protocol.File{
+	serializer.JSON(c, http.StatusOK, protocol.File{
 		DateUpdated:    dateUpdated,
 		Identifier:     fmt.Sprintf("%s/assets?%s", uri.String(), c.Request.URL.Query().Encode()),
 		IdentifierNext: identifierNext,
+		Resolved:       getResolved(c, instance),
 		Total:          total,
 		List:           assetList,
 	})
 }
 
 func getAssetListByInstance(c *gin.Context, instance rss3uri.Instance, request GetAssetListRequest) ([]model.Asset, int64, error) {
-	if len(request.LastIdentifier) == 0 {
-		if err := indexer.GetItems(c.Request.URL.String(), instance, request.Latest); err != nil {
+	if len(request.Cursor) == 0 {
+		if err := indexer.GetItems(c.Request.Context(), instance, request.Latest); err != nil {
 			return nil, 0, err
 		}
 	}
 
-	// Get instance's notes
-	internalDB := database.DB
+	return dao.BatchGetAssetList(m.BatchGetNodeListRequest{
+		Limit:        request.Limit,
+		Cursor:       request.Cursor,
+		Tags:         request.Tags,
+		ExcludeTags:  request.ExcludeTags,
+		ItemSources:  request.ItemSources,
+		Networks:     request.Networks,
+		MimeTypes:    request.MimeTypes,
+		DateFrom:     request.DateFrom,
+		DateTo:       request.DateTo,
+		InstanceList: []rss3uri.Instance{instance},
+	})
+}
 
-	if request.LastIdentifier != "" {
-		var lastItem model.Asset
-		if err := database.DB.Where(&model.Asset{
-			Identifier: strings.ToLower(request.LastIdentifier),
-		}).First(&lastItem).Error; err != nil {
-			return nil, 0, err
-		}
+// BatchGetAssetListHandlerFunc can batch query assets by request body.
+func BatchGetAssetListHandlerFunc(c *gin.Context) {
+	req := m.BatchGetNodeListRequest{}
 
-		internalDB = internalDB.
-			Where("date_created <= ?", lastItem.DateCreated).
-			Where("identifier != ?", lastItem.Identifier)
-	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
 
-	if request.Tags != nil && len(request.Tags) != 0 {
-		internalDB = internalDB.Where("tags && ?", pq.StringArray(request.Tags))
+		return
 	}
 
-	if request.ExcludeTags != nil && len(request.ExcludeTags) != 0 {
-		internalDB = internalDB.Where("tags && ? = FALSE", pq.StringArray(request.ExcludeTags))
-	}
+	if req.LastIdentifier != "" {
+		api.SetError(c, api.ErrorInvalidParams, cursor.ErrLastIdentifier)
 
-	if request.ItemSources != nil && len(request.ItemSources) != 0 {
-		internalDB = internalDB.Where("source IN ?", request.ItemSources)
+		return
 	}
 
-	if request.Networks != nil && len(request.Networks) != 0 {
-		internalDB = internalDB.Where("metadata_network IN ?", request.Networks)
+	if req.Limit <= 0 {
+		req.Limit = middleware.DefaultListLimit
 	}
 
-	assets := make([]model.Asset, 0)
-	if err := internalDB.
-		Where("owner = ?", strings.ToLower(rss3uri.New(instance).String())).
-		Limit(request.Limit).
-		Order("date_created DESC").
-		Order("contract_address DESC").
-		Order("token_id DESC").
-		Find(&assets).Error; err != nil {
-		return nil, 0, err
+	if req.Limit > middleware.MaxListLimit {
+		req.Limit = middleware.MaxListLimit
 	}
 
-	var count int64
+	resp, errType, err := service.BatchGetAssetList(c.Request.Context(), req)
+	if err != nil {
+		api.SetError(c, errType, err)
 
-	if err := internalDB.
-		Model(&model.Asset{}).
-		Where("owner = ?", strings.ToLower(rss3uri.New(instance).String())).
-		Order("date_created DESC").
-		Order("contract_address DESC").
-		Order("token_id DESC").
-		Count(&count).Error; err != nil {
-		return nil, 0, err
+		return
 	}
 
-	return assets, count, nil
+	serializer.JSON(c, http.StatusOK, resp)
 }
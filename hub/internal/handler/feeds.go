@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/api"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/feed"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	TracerNameGetNoteFeed = "get_note_feed"
+)
+
+// GetNoteFeedHandlerFunc renders the notes of an instance as a standard feed,
+// accepting the same query parameters as GetNoteListHandlerFunc.
+func GetNoteFeedHandlerFunc(format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tracer := otel.Tracer(TracerNameGetNoteFeed)
+
+		ctx, httpSnap := tracer.Start(c.Request.Context(), "http_handler")
+
+		defer httpSnap.End()
+
+		file, noteList, errType, err := getNoteListFile(ctx, c, fmt.Sprintf("notes.%s", format))
+		if err != nil {
+			httpSnap.RecordError(err)
+			httpSnap.SetStatus(codes.Error, err.Error())
+
+			api.SetError(c, errType, err)
+
+			return
+		}
+
+		instance, err := middleware.GetPlatformInstance(c)
+		if err != nil {
+			api.SetError(c, api.ErrorInvalidParams, err)
+
+			return
+		}
+
+		if notModified(c, file, getOwner(instance)) {
+			return
+		}
+
+		noteFeed := feed.Feed{
+			Title:       instance.String(),
+			Link:        getRequestURL(c, c.Request.URL.RawQuery),
+			Description: fmt.Sprintf("Notes of %s", instance.String()),
+			Items:       noteList,
+		}
+
+		if file.DateUpdated != nil {
+			noteFeed.Updated = file.DateUpdated.Time()
+		}
+
+		if _, nextQuery, found := strings.Cut(file.IdentifierNext, "?"); found {
+			noteFeed.NextLink = getRequestURL(c, nextQuery)
+		}
+
+		content, err := noteFeed.Render(format)
+		if err != nil {
+			api.SetError(c, api.ErrorUnknown, err)
+
+			return
+		}
+
+		c.Data(http.StatusOK, feed.ContentType(format), []byte(content))
+	}
+}
+
+// getRequestURL returns the absolute url of the current request with the given query.
+// X-Forwarded-Proto is only believed from the trusted proxies, see `hub.server.trusted_proxies`.
+func getRequestURL(c *gin.Context, rawQuery string) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+
+	if _, trusted := c.RemoteIP(); trusted {
+		if forwardedProto := c.GetHeader("X-Forwarded-Proto"); forwardedProto == "http" || forwardedProto == "https" {
+			scheme = forwardedProto
+		}
+	}
+
+	requestURL := fmt.Sprintf("%s://%s%s", scheme, c.Request.Host, c.Request.URL.Path)
+	if rawQuery != "" {
+		requestURL = fmt.Sprintf("%s?%s", requestURL, rawQuery)
+	}
+
+	return requestURL
+}
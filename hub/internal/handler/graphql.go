@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/api"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/graphql"
+	"github.com/gin-gonic/gin"
+)
+
+// GraphQLHandlerFunc serves GraphQL queries, through GET with the variables as a JSON query parameter
+// or through POST with a JSON body. Errors of the query itself are reported in the result.
+func GraphQLHandlerFunc(c *gin.Context) {
+	request := graphql.Request{}
+
+	if c.Request.Method == http.MethodGet {
+		if err := c.ShouldBindQuery(&request); err != nil {
+			api.SetError(c, api.ErrorInvalidParams, err)
+
+			return
+		}
+
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				api.SetError(c, api.ErrorInvalidParams, err)
+
+				return
+			}
+		}
+	} else if err := c.ShouldBindJSON(&request); err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, graphql.Do(c.Request.Context(), request))
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/api"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/middleware"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/serializer"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/service"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetItemHandlerFunc returns a single note or asset by its RSS3 URI.
+func GetItemHandlerFunc(c *gin.Context) {
+	instance, err := middleware.GetNetworkInstance(c)
+	if err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	switch constants.PrefixName(instance.GetPrefix()) {
+	case constants.PrefixNameNote, constants.PrefixNameAsset:
+	default:
+		api.SetError(c, api.ErrorInvalidParams, fmt.Errorf("unsupported prefix %s", instance.GetPrefix()))
+
+		return
+	}
+
+	item, err := getItemByInstance(c, instance)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			api.SetError(c, api.ErrorNotFound, err)
+		} else {
+			api.SetError(c, api.ErrorDatabase, err)
+		}
+
+		return
+	}
+
+	serializer.JSON(c, http.StatusOK, item)
+}
+
+func getItemByInstance(c *gin.Context, instance *rss3uri.NetworkInstance) (*protocol.Item, error) {
+	identifier := strings.ToLower(rss3uri.New(instance).String())
+
+	// Some identifiers carry a fragment, e.g. native token transfers end with `#eth`
+	if _, fragment, found := strings.Cut(c.Param("instance"), "#"); found {
+		identifier = fmt.Sprintf("%s#%s", identifier, strings.ToLower(fragment))
+	}
+
+	var (
+		itemList []protocol.Item
+		err      error
+	)
+
+	switch constants.PrefixName(instance.GetPrefix()) {
+	case constants.PrefixNameNote:
+		var note model.Note
+		if err := database.DB.Where("identifier = ?", identifier).First(&note).Error; err != nil {
+			return nil, err
+		}
+
+		itemList, _, _, err = service.FormatProtocolItemByNote([]model.Note{note})
+	case constants.PrefixNameAsset:
+		var asset model.Asset
+		if err := database.DB.Where("identifier = ?", identifier).First(&asset).Error; err != nil {
+			return nil, err
+		}
+
+		itemList, _, _, err = service.FormatProtocolItemByAsset([]model.Asset{asset})
+	default:
+		return nil, fmt.Errorf("unsupported prefix %s", instance.GetPrefix())
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &itemList[0], nil
+}
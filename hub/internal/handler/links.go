@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/api"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/cursor"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/dao"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/middleware"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/serializer"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/service"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
+	"github.com/gin-gonic/gin"
+)
+
+type GetLinkListRequest struct {
+	Limit       int      `form:"limit"`
+	Cursor      string   `form:"cursor"`
+	Types       []string `form:"types"`
+	LinkSources []string `form:"link_sources"`
+
+	LastIdentifier string `form:"last_identifier"` // replaced by Cursor, only bound to be rejected
+}
+
+// GetLinkListHandlerFunc returns links that start from the instance.
+func GetLinkListHandlerFunc(c *gin.Context) {
+	getLinkList(c, false)
+}
+
+// GetBackLinkListHandlerFunc returns links that point to the instance.
+func GetBackLinkListHandlerFunc(c *gin.Context) {
+	getLinkList(c, true)
+}
+
+// nolint:funlen // TODO
+func getLinkList(c *gin.Context, backlink bool) {
+	instance, err := middleware.GetInstance(c)
+	if err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	request := GetLinkListRequest{}
+	if err = c.ShouldBindQuery(&request); err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	if request.LastIdentifier != "" {
+		api.SetError(c, api.ErrorInvalidParams, cursor.ErrLastIdentifier)
+
+		return
+	}
+
+	types := make([]int, 0, len(request.Types))
+
+	for _, linkType := range request.Types {
+		linkTypeID := constants.LinkTypeName(linkType).ID()
+		if linkTypeID == constants.LinkTypeUnknown {
+			api.SetError(c, api.ErrorInvalidParams, fmt.Errorf("invalid link type %s", linkType))
+
+			return
+		}
+
+		types = append(types, linkTypeID.Int())
+	}
+
+	linkSources := make([]int, 0, len(request.LinkSources))
+
+	for _, linkSource := range request.LinkSources {
+		linkSourceID := constants.LinkSourceName(linkSource).ID()
+		if linkSourceID == constants.LinkSourceIDUnknown {
+			api.SetError(c, api.ErrorInvalidParams, fmt.Errorf("invalid link source %s", linkSource))
+
+			return
+		}
+
+		linkSources = append(linkSources, linkSourceID.Int())
+	}
+
+	linkModels, total, err := dao.GetLinkList(
+		strings.ToLower(rss3uri.New(instance).String()), types, linkSources, backlink, request.Cursor, request.Limit,
+	)
+	if err != nil {
+		if errors.Is(err, cursor.ErrInvalidCursor) {
+			api.SetError(c, api.ErrorInvalidParams, err)
+		} else {
+			api.SetError(c, api.ErrorDatabase, err)
+		}
+
+		return
+	}
+
+	linkList, dateUpdated, errType, err := service.FormatProtocolLink(linkModels)
+	if err != nil {
+		api.SetError(c, errType, err)
+
+		return
+	}
+
+	path := "links"
+	if backlink {
+		path = "backlinks"
+	}
+
+	identifierNext := ""
+	uri := rss3uri.New(instance)
+
+	if total > int64(request.Limit) && len(linkModels) > 0 {
+		nextCursor, err := dao.LinkCursor(linkModels[len(linkModels)-1], backlink)
+		if err != nil {
+			api.SetError(c, api.ErrorUnknown, err)
+
+			return
+		}
+
+		nextQuery := c.Request.URL.Query()
+		nextQuery.Set("cursor", nextCursor)
+
+		identifierNext = fmt.Sprintf("%s/%s?%s", uri.String(), path, nextQuery.Encode())
+	}
+
+	serializer.JSON(c, http.StatusOK, protocol.File{
+		DateUpdated:    dateUpdated,
+		Identifier:     fmt.Sprintf("%s/%s?%s", uri.String(), path, c.Request.URL.Query().Encode()),
+		IdentifierNext: identifierNext,
+		Resolved:       getResolved(c, instance),
+		Total:          total,
+		List:           linkList,
+	})
+}
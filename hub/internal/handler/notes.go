@@ -2,21 +2,28 @@ package handler
 
 import (
 	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/api"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/cursor"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/dao"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/indexer"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/middleware"
 	m "github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/model"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/responsecache"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/serializer"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/service"
-	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
 	"github.com/gin-gonic/gin"
-	"github.com/lib/pq"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
 )
@@ -27,14 +34,24 @@ const (
 )
 
 type GetNoteListRequest struct {
-	Limit          int      `form:"limit"`
-	LastIdentifier string   `form:"last_identifier"`
-	Tags           []string `form:"tags"`
-	ExcludeTags    []string `form:"exclude_tags"`
-	MimeTypes      []string `form:"mime_types"`
-	ItemSources    []string `form:"item_sources"`
-	Networks       []string `form:"networks"`
-	Latest         bool     `form:"latest"`
+	Limit       int       `form:"limit"`
+	Cursor      string    `form:"cursor"`
+	Tags        []string  `form:"tags"`
+	ExcludeTags []string  `form:"exclude_tags"`
+	MimeTypes   []string  `form:"mime_types"`
+	ItemSources []string  `form:"item_sources"`
+	Networks    []string  `form:"networks"`
+	DateFrom    time.Time `form:"date_from"`
+	DateTo      time.Time `form:"date_to"`
+	Latest      bool      `form:"latest"`
+
+	LastIdentifier string `form:"last_identifier"` // replaced by Cursor, only bound to be rejected
+}
+
+// noteListCache is cached instead of the File, which depends on the path
+type noteListCache struct {
+	Notes []model.Note `json:"notes"`
+	Total int64        `json:"total"`
 }
 
 func GetNoteListHandlerFunc(c *gin.Context) {
@@ -46,75 +63,126 @@ func GetNoteListHandlerFunc(c *gin.Context) {
 
 	instance, err := middleware.GetPlatformInstance(c)
 	if err != nil {
-		httpSnap.RecordError(err)
-		httpSnap.SetStatus(codes.Error, err.Error())
-
 		api.SetError(c, api.ErrorInvalidParams, err)
 
 		return
 	}
 
-	request := GetNoteListRequest{}
-	if err = c.ShouldBindQuery(&request); err != nil {
+	file, _, errType, err := getNoteListFile(ctx, c, "notes")
+	if err != nil {
 		httpSnap.RecordError(err)
 		httpSnap.SetStatus(codes.Error, err.Error())
 
-		api.SetError(c, api.ErrorInvalidParams, err)
+		api.SetError(c, errType, err)
 
 		return
 	}
 
-	noteModels, total, err := getNoteListByInstance(ctx, c, instance, request)
+	if notModified(c, file, getOwner(instance)) {
+		return
+	}
 
+	serializer.JSON(c, http.StatusOK, file)
+}
+
+// notModified sets the ETag of the file and responds 304 if the client already has it.
+// The ETag changes whenever the responses of the owners are invalidated, see responsecache.Generation.
+func notModified(c *gin.Context, file *protocol.File, owners ...string) bool {
+	generation, err := responsecache.Generation(c.Request.Context(), owners...)
 	if err != nil {
-		api.SetError(c, api.ErrorIndexer, err)
+		logger.Errorf("get the generation of %v: %v", owners, err)
 
-		return
+		return false
 	}
 
-	noteList, dateUpdated, errType, err := service.FormatProtocolItemByNote(noteModels)
+	var dateUpdated int64
+
+	if file.DateUpdated != nil {
+		dateUpdated = file.DateUpdated.Time().UnixNano()
+	}
+
+	generationHash := sha256.Sum256([]byte(generation))
+
+	etag := fmt.Sprintf(`W/"%x-%x-%x"`, file.Total, dateUpdated, generationHash[:8])
+
+	c.Header("ETag", etag)
+
+	for _, value := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		value = strings.TrimSpace(value)
+
+		// ETags are compared weakly
+		if value == "*" || strings.TrimPrefix(value, "W/") == strings.TrimPrefix(etag, "W/") {
+			c.Status(http.StatusNotModified)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// getNoteListFile queries the notes of the instance and builds a File whose identifiers point to the path.
+func getNoteListFile(ctx context.Context, c *gin.Context, path string) (*protocol.File, []protocol.Item, error, error) {
+	instance, err := middleware.GetPlatformInstance(c)
 	if err != nil {
-		api.SetError(c, errType, err)
+		return nil, nil, api.ErrorInvalidParams, err
+	}
 
-		return
+	request := GetNoteListRequest{}
+	if err = c.ShouldBindQuery(&request); err != nil {
+		return nil, nil, api.ErrorInvalidParams, err
+	}
+
+	if request.LastIdentifier != "" {
+		return nil, nil, api.ErrorInvalidParams, cursor.ErrLastIdentifier
 	}
 
-	var lastItem *protocol.Item
+	noteModels, total, err := getNoteListByInstance(ctx, c, instance, request)
+	if err != nil {
+		if errors.Is(err, cursor.ErrInvalidCursor) {
+			return nil, nil, api.ErrorInvalidParams, err
+		}
 
-	if len(noteList) > 0 {
-		lastItem = &noteList[len(noteList)-1]
+		return nil, nil, api.ErrorIndexer, err
+	}
+
+	noteList, dateUpdated, errType, err := service.FormatProtocolItemByNote(noteModels)
+	if err != nil {
+		return nil, nil, errType, err
 	}
 
 	identifierNext := ""
 	uri := rss3uri.New(instance)
 
-	if total > int64(request.Limit) {
-		nextQuery := c.Request.URL.Query()
-
-		if lastItem != nil {
-			nextQuery.Set("last_identifier", lastItem.Identifier)
+	if total > int64(request.Limit) && len(noteModels) > 0 {
+		nextCursor, err := dao.NoteCursor(noteModels[len(noteModels)-1])
+		if err != nil {
+			return nil, nil, api.ErrorUnknown, err
 		}
 
-		identifierNext = fmt.Sprintf("%s/notes?%s", uri.String(), nextQuery.Encode())
+		nextQuery := c.Request.URL.Query()
+		nextQuery.Set("cursor", nextCursor)
+
+		identifierNext = fmt.Sprintf("%s/%s?%s", uri.String(), path, nextQuery.Encode())
 	}
 
-	c.JSON(http.StatusOK, protocol.File{
+	return &protocol.File{
 		DateUpdated:    dateUpdated,
-		Identifier:     fmt.Sprintf("%s/notes?%s", uri.String(), c.Request.URL.Query().Encode()),
+		Identifier:     fmt.Sprintf("%s/%s?%s", uri.String(), path, c.Request.URL.Query().Encode()),
 		IdentifierNext: identifierNext,
+		Resolved:       getResolved(c, instance),
 		Total:          total,
 		List:           noteList,
-	})
+	}, noteList, nil, nil
 }
 
-// nolint:funlen // TODO
 func getNoteListByInstance(ctx context.Context, c *gin.Context, instance rss3uri.Instance, request GetNoteListRequest) ([]model.Note, int64, error) {
 	tracer := otel.Tracer(TracerNameGetNoteLists)
 
-	ctx, indexerSnap := tracer.Start(ctx, "indexer")
+	_, indexerSnap := tracer.Start(ctx, "indexer")
 
-	if len(request.LastIdentifier) == 0 {
-		if err := indexer.GetItems(c.Request.URL.String(), instance, request.Latest); err != nil {
+	if len(request.Cursor) == 0 {
+		if err := indexer.GetItems(c.Request.Context(), instance, request.Latest); err != nil {
 			indexerSnap.RecordError(err)
 			indexerSnap.SetStatus(codes.Error, err.Error())
 
@@ -124,87 +192,100 @@ func getNoteListByInstance(ctx context.Context, c *gin.Context, instance rss3uri
 
 	indexerSnap.End()
 
-	ctx, databaseSnap := tracer.Start(ctx, "database")
+	batchRequest := getItemListRequest(instance, request)
 
-	defer databaseSnap.End()
+	_, cacheSnap := tracer.Start(ctx, "cache")
 
-	ctx, databaseSelectSnap := tracer.Start(ctx, "database_select")
+	cachedNoteList := noteListCache{}
 
-	// Get instance's notes
-	internalDB := database.DB
-
-	if request.LastIdentifier != "" {
-		var lastItem model.Note
-		if err := database.DB.Where(&model.Note{
-			Identifier: strings.ToLower(request.LastIdentifier),
-		}).First(&lastItem).Error; err != nil {
-			databaseSnap.RecordError(err)
-			databaseSnap.SetStatus(codes.Error, err.Error())
-
-			return nil, 0, err
+	// The cache is bypassed after a synchronous crawl, its invalidation might still be on the way
+	cacheKey, err := responsecache.Key(ctx, getOwner(instance), "notes", normalizeNoteListRequest(batchRequest))
+	if err != nil {
+		logger.Errorf("get note list cache key: %v", err)
+	} else if !request.Latest {
+		found, err := responsecache.Get(ctx, cacheKey, &cachedNoteList)
+		if err != nil {
+			logger.Errorf("get note list cache: %v", err)
 		}
 
-		internalDB = internalDB.
-			Where("date_created <= ?", lastItem.DateCreated).
-			Where("identifier != ?", lastItem.Identifier).
-			Where(
-				"(transaction_hash != ?) OR (transaction_hash = ? AND transaction_log_index < ?)",
-				lastItem.TransactionHash, lastItem.TransactionHash, lastItem.TransactionLogIndex,
-			)
-	}
+		if found {
+			cacheSnap.End()
 
-	if request.Tags != nil && len(request.Tags) != 0 {
-		internalDB = internalDB.Where("tags && ?", pq.StringArray(request.Tags))
+			return cachedNoteList.Notes, cachedNoteList.Total, nil
+		}
 	}
 
-	if request.ExcludeTags != nil && len(request.ExcludeTags) != 0 {
-		internalDB = internalDB.Where("tags && ? = FALSE", pq.StringArray(request.ExcludeTags))
-	}
+	cacheSnap.End()
 
-	if request.ItemSources != nil && len(request.ItemSources) != 0 {
-		internalDB = internalDB.Where("source IN ?", request.ItemSources)
-	}
+	_, databaseSnap := tracer.Start(ctx, "database")
 
-	if request.Networks != nil && len(request.Networks) != 0 {
-		internalDB = internalDB.Where("metadata_network IN ?", request.Networks)
-	}
+	defer databaseSnap.End()
 
-	notes := make([]model.Note, 0)
-	if err := internalDB.
-		Where("owner = ?", strings.ToLower(rss3uri.New(instance).String())).
-		Limit(request.Limit).
-		Order("date_created DESC").
-		Order("transaction_hash DESC").
-		Order("transaction_log_index DESC").
-		Find(&notes).Error; err != nil {
+	notes, count, err := dao.BatchGetNodeList(batchRequest)
+	if err != nil {
 		databaseSnap.RecordError(err)
 		databaseSnap.SetStatus(codes.Error, err.Error())
 
 		return nil, 0, err
 	}
 
-	databaseSelectSnap.End()
+	if cacheKey != "" {
+		if err := responsecache.Set(ctx, cacheKey, noteListCache{Notes: notes, Total: count}); err != nil {
+			logger.Errorf("set note list cache: %v", err)
+		}
+	}
 
-	_, databaseCountSnap := tracer.Start(ctx, "database_count")
+	return notes, count, nil
+}
 
-	var count int64
+// getItemListRequest applies the owner and filters of the request, which the stats share
+func getItemListRequest(instance rss3uri.Instance, request GetNoteListRequest) m.BatchGetNodeListRequest {
+	return m.BatchGetNodeListRequest{
+		Limit:        request.Limit,
+		Cursor:       request.Cursor,
+		Tags:         request.Tags,
+		ExcludeTags:  request.ExcludeTags,
+		ItemSources:  request.ItemSources,
+		Networks:     request.Networks,
+		MimeTypes:    request.MimeTypes,
+		DateFrom:     request.DateFrom,
+		DateTo:       request.DateTo,
+		InstanceList: []rss3uri.Instance{instance},
+	}
+}
 
-	if err := internalDB.
-		Model(&model.Note{}).
-		Where("owner = ?", strings.ToLower(rss3uri.New(instance).String())).
-		Order("date_created DESC").
-		Order("transaction_hash DESC").
-		Order("transaction_log_index DESC").
-		Count(&count).Error; err != nil {
-		databaseSnap.RecordError(err)
-		databaseSnap.SetStatus(codes.Error, err.Error())
+// getOwner returns the owner column of the items of the instance
+func getOwner(instance rss3uri.Instance) string {
+	return strings.ToLower(rss3uri.New(instance).String())
+}
 
-		return nil, 0, err
+// getResolved reports the name of the instance if it was requested by name
+func getResolved(c *gin.Context, instance rss3uri.Instance) *protocol.Resolved {
+	name, ok := middleware.GetName(c)
+	if !ok {
+		return nil
 	}
 
-	databaseCountSnap.End()
+	return &protocol.Resolved{
+		Name:    name,
+		Address: instance.GetIdentity(),
+	}
+}
 
-	return notes, count, nil
+// normalizeNoteListRequest makes equivalent requests share a cache key
+func normalizeNoteListRequest(request m.BatchGetNodeListRequest) m.BatchGetNodeListRequest {
+	for _, values := range []*[]string{&request.Tags, &request.ExcludeTags, &request.ItemSources, &request.Networks, &request.MimeTypes} {
+		sortedValues := append([]string{}, *values...)
+		sort.Strings(sortedValues)
+		*values = sortedValues
+	}
+
+	request.DateFrom = request.DateFrom.UTC()
+	request.DateTo = request.DateTo.UTC()
+	request.Latest = false
+	request.InstanceList = nil
+
+	return request
 }
 
 // BatchGetNoteListHandlerFunc can batch query notes by request body.
@@ -226,6 +307,12 @@ func BatchGetNoteListHandlerFunc(c *gin.Context) {
 		return
 	}
 
+	if req.LastIdentifier != "" {
+		api.SetError(c, api.ErrorInvalidParams, cursor.ErrLastIdentifier)
+
+		return
+	}
+
 	if req.Limit <= 0 {
 		req.Limit = middleware.DefaultListLimit
 	}
@@ -244,5 +331,5 @@ func BatchGetNoteListHandlerFunc(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	serializer.JSON(c, http.StatusOK, resp)
 }
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/api"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/dao"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/middleware"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/serializer"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/service"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
+	"github.com/gin-gonic/gin"
+)
+
+type GetProfileListRequest struct {
+	Limit          int      `form:"limit"`
+	LastIdentifier string   `form:"last_identifier"`
+	ProfileSources []string `form:"profile_sources"`
+}
+
+func GetProfileListHandlerFunc(c *gin.Context) {
+	instance, err := middleware.GetPlatformInstance(c)
+	if err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	request := GetProfileListRequest{}
+	if err = c.ShouldBindQuery(&request); err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	profileSourceIDs := make([]int, 0, len(request.ProfileSources))
+
+	for _, profileSource := range request.ProfileSources {
+		profileSourceID := constants.ProfileSourceName(profileSource).ID()
+		if profileSourceID == constants.ProfileSourceIDUnknown {
+			api.SetError(c, api.ErrorInvalidParams, fmt.Errorf("invalid profile source %s", profileSource))
+
+			return
+		}
+
+		profileSourceIDs = append(profileSourceIDs, profileSourceID.Int())
+	}
+
+	profileModels, total, err := getProfileListByInstance(instance, request, profileSourceIDs)
+	if err != nil {
+		api.SetError(c, api.ErrorDatabase, err)
+
+		return
+	}
+
+	profileList, dateUpdated, errType, err := service.FormatProtocolProfile(profileModels)
+	if err != nil {
+		api.SetError(c, errType, err)
+
+		return
+	}
+
+	identifierNext := ""
+	uri := rss3uri.New(instance)
+
+	if total > int64(request.Limit) && len(profileList) > 0 {
+		nextQuery := c.Request.URL.Query()
+		nextQuery.Set("last_identifier", profileList[len(profileList)-1].Identifier)
+
+		identifierNext = fmt.Sprintf("%s/profiles?%s", uri.String(), nextQuery.Encode())
+	}
+
+	serializer.JSON(c, http.StatusOK, protocol.File{
+		DateUpdated:    dateUpdated,
+		Identifier:     fmt.Sprintf("%s/profiles?%s", uri.String(), c.Request.URL.Query().Encode()),
+		IdentifierNext: identifierNext,
+		Resolved:       getResolved(c, instance),
+		Total:          total,
+		List:           profileList,
+	})
+}
+
+func getProfileListByInstance(
+	instance rss3uri.Instance, request GetProfileListRequest, profileSourceIDs []int,
+) ([]model.Profile, int64, error) {
+	var lastProfile *model.Profile
+
+	if request.LastIdentifier != "" {
+		key, err := service.ParseProfileIdentifier(request.LastIdentifier)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		lastProfile = key
+	}
+
+	return dao.GetProfileList(instance, request.Limit, lastProfile, profileSourceIDs)
+}
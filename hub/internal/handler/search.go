@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/api"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/serializer"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/service"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/search"
+	"github.com/gin-gonic/gin"
+)
+
+const MaxSearchLimit = 100
+
+type SearchRequest struct {
+	Query    string   `form:"query"`
+	Types    []string `form:"types"` // note, asset
+	Owners   []string `form:"owners"`
+	Tags     []string `form:"tags"`
+	Networks []string `form:"networks"`
+	Limit    int      `form:"limit"`
+	Offset   int      `form:"offset"`
+}
+
+func SearchHandlerFunc(c *gin.Context) {
+	request := SearchRequest{}
+	if err := c.ShouldBindQuery(&request); err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	query, err := newSearchQuery(request)
+	if err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	itemList, dateUpdated, total, errType, err := service.Search(c.Request.Context(), *query)
+	if err != nil {
+		api.SetError(c, errType, err)
+
+		return
+	}
+
+	// Search results aren't owned by an instance, so identifiers are the URLs of the hub
+	identifierNext := ""
+
+	if nextOffset := query.Offset + query.Limit; int64(nextOffset) < total && nextOffset < search.MaxResultWindow {
+		nextQuery := c.Request.URL.Query()
+		nextQuery.Set("offset", strconv.Itoa(nextOffset))
+
+		identifierNext = fmt.Sprintf("%s?%s", c.Request.URL.Path, nextQuery.Encode())
+	}
+
+	serializer.JSON(c, http.StatusOK, protocol.File{
+		DateUpdated:    dateUpdated,
+		Identifier:     fmt.Sprintf("%s?%s", c.Request.URL.Path, c.Request.URL.Query().Encode()),
+		IdentifierNext: identifierNext,
+		Total:          total,
+		List:           itemList,
+	})
+}
+
+func newSearchQuery(request SearchRequest) (*search.Query, error) {
+	query := search.Query{
+		Text:     strings.TrimSpace(request.Query),
+		Tags:     request.Tags,
+		Networks: request.Networks,
+		Offset:   request.Offset,
+		Limit:    request.Limit,
+	}
+
+	for _, kind := range request.Types {
+		switch kind {
+		case search.KindNote, search.KindAsset:
+			query.Kinds = append(query.Kinds, kind)
+		default:
+			return nil, fmt.Errorf("invalid type %s", kind)
+		}
+	}
+
+	// Owners are stored as lowercase RSS3 URIs
+	for _, owner := range request.Owners {
+		uri, err := rss3uri.Parse(owner)
+		if err != nil {
+			return nil, fmt.Errorf("invalid owner %s: %w", owner, err)
+		}
+
+		query.Owners = append(query.Owners, strings.ToLower(uri.String()))
+	}
+
+	if query.Limit <= 0 || query.Limit > MaxSearchLimit {
+		query.Limit = MaxSearchLimit
+	}
+
+	if query.Offset < 0 || query.Offset+query.Limit > search.MaxResultWindow {
+		return nil, fmt.Errorf("offset must be between 0 and %d", search.MaxResultWindow-query.Limit)
+	}
+
+	return &query, nil
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/api"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/dao"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/indexer"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/middleware"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/responsecache"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/serializer"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/timex"
+	"github.com/gin-gonic/gin"
+)
+
+type statsCache struct {
+	Notes  dao.ItemStats `json:"notes"`
+	Assets dao.ItemStats `json:"assets"`
+}
+
+// GetStatsHandlerFunc counts the notes and assets of the instance,
+// it takes the filters of the note list except for the limit and cursor.
+func GetStatsHandlerFunc(c *gin.Context) {
+	instance, err := middleware.GetPlatformInstance(c)
+	if err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	request := GetNoteListRequest{}
+	if err = c.ShouldBindQuery(&request); err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	if err := indexer.GetItems(c.Request.Context(), instance, request.Latest); err != nil {
+		api.SetError(c, api.ErrorIndexer, err)
+
+		return
+	}
+
+	stats, err := getStatsByInstance(c.Request.Context(), instance, request)
+	if err != nil {
+		api.SetError(c, api.ErrorDatabase, err)
+
+		return
+	}
+
+	serializer.JSON(c, http.StatusOK, protocol.Stats{
+		Identifier: fmt.Sprintf("%s/stats?%s", rss3uri.New(instance).String(), c.Request.URL.Query().Encode()),
+		Resolved:   getResolved(c, instance),
+		Notes:      formatItemStats(stats.Notes),
+		Assets:     formatItemStats(stats.Assets),
+	})
+}
+
+func getStatsByInstance(ctx context.Context, instance rss3uri.Instance, request GetNoteListRequest) (*statsCache, error) {
+	request.Limit, request.Cursor = 0, ""
+
+	batchRequest := getItemListRequest(instance, request)
+
+	stats := statsCache{}
+
+	// The cache is bypassed after a synchronous crawl as for the note list
+	cacheKey, err := responsecache.Key(ctx, getOwner(instance), "stats", normalizeNoteListRequest(batchRequest))
+	if err != nil {
+		logger.Errorf("get stats cache key: %v", err)
+	} else if !request.Latest {
+		found, err := responsecache.Get(ctx, cacheKey, &stats)
+		if err != nil {
+			logger.Errorf("get stats cache: %v", err)
+		}
+
+		if found {
+			return &stats, nil
+		}
+	}
+
+	noteStats, err := dao.GetNoteStats(batchRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	assetStats, err := dao.GetAssetStats(batchRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	stats = statsCache{Notes: *noteStats, Assets: *assetStats}
+
+	if cacheKey != "" {
+		if err := responsecache.Set(ctx, cacheKey, stats); err != nil {
+			logger.Errorf("set stats cache: %v", err)
+		}
+	}
+
+	return &stats, nil
+}
+
+func formatItemStats(stats dao.ItemStats) protocol.ItemStats {
+	itemStats := protocol.ItemStats{
+		Total:    stats.Total,
+		Tags:     stats.Tags,
+		Networks: stats.Networks,
+		Sources:  stats.Sources,
+		Months:   stats.Months,
+	}
+
+	if stats.DateFirst != nil {
+		dateFirst := timex.Time(*stats.DateFirst)
+		itemStats.DateFirst = &dateFirst
+	}
+
+	if stats.DateLast != nil {
+		dateLast := timex.Time(*stats.DateLast)
+		itemStats.DateLast = &dateLast
+	}
+
+	return itemStats
+}
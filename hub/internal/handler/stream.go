@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/api"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/serializer"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/service"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/stream"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/config"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxStreamInstances = 100
+	streamBatchLimit   = 1000
+
+	// streamLookback re-scans recently inserted notes, since concurrent transactions
+	// may commit in a different order than their created_at
+	streamLookback = 10 * time.Second
+	// streamHeartbeat keeps proxies from closing idle streams and polls in case a notification was lost
+	streamHeartbeat = 15 * time.Second
+	// streamDeadlineMargin closes streams before the server write timeout cuts them off
+	streamDeadlineMargin = 5 * time.Second
+)
+
+type GetNoteStreamRequest struct {
+	Instances   []string `form:"instances" binding:"required"`
+	ResumeToken string   `form:"resume_token"`
+}
+
+// GetNoteStreamHandlerFunc pushes the newly inserted notes of the instances as server-sent events.
+// Each event id is a resume token, passed back through Last-Event-ID or resume_token
+// it continues the stream after that note. Delivery is at least once, so clients
+// should deduplicate by identifier.
+// nolint:funlen // TODO
+func GetNoteStreamHandlerFunc(c *gin.Context) {
+	request := GetNoteStreamRequest{}
+	if err := c.ShouldBindQuery(&request); err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	if len(request.Instances) > maxStreamInstances {
+		api.SetError(c, api.ErrorInvalidParams, fmt.Errorf("at most %d instances", maxStreamInstances))
+
+		return
+	}
+
+	owners := make([]string, 0, len(request.Instances))
+
+	for _, rawInstance := range request.Instances {
+		instance, err := rss3uri.ParseInstance(rawInstance)
+		if err != nil {
+			api.SetError(c, api.ErrorInvalidParams, err)
+
+			return
+		}
+
+		if _, ok := instance.(*rss3uri.PlatformInstance); !ok {
+			api.SetError(c, api.ErrorInvalidParams, fmt.Errorf("%s is not an account instance", rawInstance))
+
+			return
+		}
+
+		owners = append(owners, strings.ToLower(rss3uri.New(instance).String()))
+	}
+
+	cursor := stream.Token{CreatedAt: time.Now()}
+
+	if resumeToken := c.GetHeader("Last-Event-ID"); resumeToken != "" {
+		request.ResumeToken = resumeToken
+	}
+
+	if request.ResumeToken != "" {
+		token, err := stream.ParseToken(request.ResumeToken)
+		if err != nil {
+			api.SetError(c, api.ErrorInvalidParams, err)
+
+			return
+		}
+
+		cursor = *token
+	}
+
+	subscription := stream.Subscribe(owners)
+	defer stream.Unsubscribe(subscription)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	var deadline <-chan time.Time
+
+	if writeTimeout := config.Config.Hub.Server.WriteTimeout; writeTimeout > streamDeadlineMargin {
+		timer := time.NewTimer(writeTimeout - streamDeadlineMargin)
+		defer timer.Stop()
+
+		deadline = timer.C
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	// sent remembers the notes pushed within the lookback window
+	sent := map[string]time.Time{}
+	backlog := false
+
+	for {
+		notes, err := getStreamNoteList(owners, cursor, backlog)
+		if err != nil {
+			logger.Errorf("get stream note list: %v", err)
+
+			return
+		}
+
+		itemList, _, _, err := service.FormatProtocolItemByNote(notes)
+		if err != nil {
+			logger.Errorf("format stream note list: %v", err)
+
+			return
+		}
+
+		for i, note := range notes {
+			if _, exists := sent[note.Identifier]; exists {
+				continue
+			}
+
+			data, err := json.Marshal(serializer.Get(c).Serialize(itemList[i]))
+			if err != nil {
+				logger.Errorf("marshal stream item: %v", err)
+
+				return
+			}
+
+			sent[note.Identifier] = note.CreatedAt
+
+			if note.CreatedAt.After(cursor.CreatedAt) ||
+				(note.CreatedAt.Equal(cursor.CreatedAt) && note.Identifier > cursor.Identifier) {
+				cursor = stream.Token{CreatedAt: note.CreatedAt, Identifier: note.Identifier}
+			}
+
+			if _, err := fmt.Fprintf(c.Writer, "id: %s\nevent: note\ndata: %s\n\n", cursor.String(), data); err != nil {
+				return
+			}
+		}
+
+		for identifier, createdAt := range sent {
+			if createdAt.Before(cursor.CreatedAt.Add(-streamLookback)) {
+				delete(sent, identifier)
+			}
+		}
+
+		c.Writer.Flush()
+
+		// Drain the backlog strictly after the cursor before waiting for notifications
+		if backlog = len(notes) == streamBatchLimit; backlog {
+			continue
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-deadline:
+			// Ask the client to reconnect right away with the last event id
+			fmt.Fprint(c.Writer, "retry: 0\n\n")
+			c.Writer.Flush()
+
+			return
+		case <-subscription.C:
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// getStreamNoteList returns the notes of the owners inserted after the lookback window of the cursor,
+// or strictly after the cursor while draining a backlog.
+func getStreamNoteList(owners []string, cursor stream.Token, backlog bool) ([]model.Note, error) {
+	internalDB := database.DB.Where("owner IN ?", owners)
+
+	if backlog {
+		internalDB = internalDB.Where("(created_at, identifier) > (?, ?)", cursor.CreatedAt, cursor.Identifier)
+	} else {
+		internalDB = internalDB.Where("created_at > ?", cursor.CreatedAt.Add(-streamLookback))
+	}
+
+	notes := make([]model.Note, 0)
+
+	if err := internalDB.
+		Order("created_at").
+		Order("identifier").
+		Limit(streamBatchLimit).
+		Find(&notes).Error; err != nil {
+		return nil, err
+	}
+
+	return notes, nil
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/api"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/cursor"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/dao"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/middleware"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/serializer"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/service"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
+	"github.com/gin-gonic/gin"
+)
+
+// GetTimelineHandlerFunc returns the notes of the accounts the instance follows,
+// it takes the filters of the note list. The followed accounts are not crawled,
+// their notes are the ones already indexed. The follows are those crawled from Crossbell.
+func GetTimelineHandlerFunc(c *gin.Context) {
+	instance, err := middleware.GetPlatformInstance(c)
+	if err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	request := GetNoteListRequest{}
+	if err = c.ShouldBindQuery(&request); err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	if request.LastIdentifier != "" {
+		api.SetError(c, api.ErrorInvalidParams, cursor.ErrLastIdentifier)
+
+		return
+	}
+
+	batchRequest := getItemListRequest(instance, request)
+	batchRequest.InstanceList = nil
+	batchRequest.FollowedBy = getOwner(instance)
+
+	noteModels, total, err := dao.BatchGetNodeList(batchRequest)
+	if err != nil {
+		if errors.Is(err, cursor.ErrInvalidCursor) {
+			api.SetError(c, api.ErrorInvalidParams, err)
+		} else {
+			api.SetError(c, api.ErrorDatabase, err)
+		}
+
+		return
+	}
+
+	noteList, dateUpdated, errType, err := service.FormatProtocolItemByNote(noteModels)
+	if err != nil {
+		api.SetError(c, errType, err)
+
+		return
+	}
+
+	uri := rss3uri.New(instance)
+
+	identifierNext := ""
+
+	if total > int64(request.Limit) && len(noteModels) > 0 {
+		nextCursor, err := dao.NoteCursor(noteModels[len(noteModels)-1])
+		if err != nil {
+			api.SetError(c, api.ErrorUnknown, err)
+
+			return
+		}
+
+		nextQuery := c.Request.URL.Query()
+		nextQuery.Set("cursor", nextCursor)
+
+		identifierNext = fmt.Sprintf("%s/timeline?%s", uri.String(), nextQuery.Encode())
+	}
+
+	file := &protocol.File{
+		DateUpdated:    dateUpdated,
+		Identifier:     fmt.Sprintf("%s/timeline?%s", uri.String(), c.Request.URL.Query().Encode()),
+		IdentifierNext: identifierNext,
+		Resolved:       getResolved(c, instance),
+		Total:          total,
+		List:           noteList,
+	}
+
+	// The notes of the followed accounts are not tracked by the generations, only the global one
+	if notModified(c, file) {
+		return
+	}
+
+	serializer.JSON(c, http.StatusOK, file)
+}
@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/api"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/netguard"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/serializer"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/service"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/webhook"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/webhook/dispatcher"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/config"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/timex"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const maxWebhookInstances = 100
+
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	Instances   []string `json:"instances" binding:"required"`
+	Tags        []string `json:"tags"`
+	Networks    []string `json:"networks"`
+	ItemSources []string `json:"item_sources"`
+}
+
+type GetWebhookDeliveryListRequest struct {
+	Limit          int    `form:"limit"`
+	LastIdentifier string `form:"last_identifier"`
+}
+
+// CreateWebhookHandlerFunc registers a webhook, its id and secret are only returned once
+// and both are required to manage it.
+func CreateWebhookHandlerFunc(c *gin.Context) {
+	request := CreateWebhookRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	// The deliveries are guarded on their own, as the host may resolve to another address later
+	if _, err := netguard.CheckURL(
+		c.Request.Context(), request.URL, config.Config.Hub.Webhook.AllowPrivateNetworks, "http", "https",
+	); err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	if len(request.Instances) == 0 || len(request.Instances) > maxWebhookInstances {
+		api.SetError(c, api.ErrorInvalidParams, fmt.Errorf("between 1 and %d instances", maxWebhookInstances))
+
+		return
+	}
+
+	owners := make([]string, 0, len(request.Instances))
+
+	for _, rawInstance := range request.Instances {
+		instance, err := rss3uri.ParseInstance(rawInstance)
+		if err != nil {
+			api.SetError(c, api.ErrorInvalidParams, err)
+
+			return
+		}
+
+		if _, ok := instance.(*rss3uri.PlatformInstance); !ok {
+			api.SetError(c, api.ErrorInvalidParams, fmt.Errorf("%s is not an account instance", rawInstance))
+
+			return
+		}
+
+		owners = append(owners, strings.ToLower(rss3uri.New(instance).String()))
+	}
+
+	webhookModel, err := dispatcher.NewWebhook(request.URL, owners, request.Tags, request.Networks, request.ItemSources)
+	if err != nil {
+		api.SetError(c, api.ErrorDatabase, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, service.FormatProtocolWebhook(*webhookModel, true))
+}
+
+func GetWebhookHandlerFunc(c *gin.Context) {
+	webhookModel, errType, err := getWebhook(c)
+	if err != nil {
+		api.SetError(c, errType, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, service.FormatProtocolWebhook(*webhookModel, false))
+}
+
+func DeleteWebhookHandlerFunc(c *gin.Context) {
+	webhookModel, errType, err := getWebhook(c)
+	if err != nil {
+		api.SetError(c, errType, err)
+
+		return
+	}
+
+	if err := database.DB.Delete(webhookModel).Error; err != nil {
+		api.SetError(c, api.ErrorDatabase, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, service.FormatProtocolWebhook(*webhookModel, false))
+}
+
+// GetWebhookDeliveryListHandlerFunc returns the delivery log of a webhook, latest first.
+func GetWebhookDeliveryListHandlerFunc(c *gin.Context) {
+	webhookModel, errType, err := getWebhook(c)
+	if err != nil {
+		api.SetError(c, errType, err)
+
+		return
+	}
+
+	request := GetWebhookDeliveryListRequest{}
+	if err := c.ShouldBindQuery(&request); err != nil {
+		api.SetError(c, api.ErrorInvalidParams, err)
+
+		return
+	}
+
+	internalDB := database.DB.Where("webhook_id = ?", webhookModel.ID)
+
+	if request.LastIdentifier != "" {
+		lastID, err := strconv.ParseInt(request.LastIdentifier, 10, 64)
+		if err != nil {
+			api.SetError(c, api.ErrorInvalidParams, err)
+
+			return
+		}
+
+		internalDB = internalDB.Where("id < ?", lastID)
+	}
+
+	deliveries := make([]model.WebhookDelivery, 0)
+	if err := internalDB.
+		Limit(request.Limit).
+		Order("id DESC").
+		Find(&deliveries).Error; err != nil {
+		api.SetError(c, api.ErrorDatabase, err)
+
+		return
+	}
+
+	var total int64
+	if err := database.DB.
+		Model(&model.WebhookDelivery{}).
+		Where("webhook_id = ?", webhookModel.ID).
+		Count(&total).Error; err != nil {
+		api.SetError(c, api.ErrorDatabase, err)
+
+		return
+	}
+
+	deliveryList, dateUpdated := service.FormatProtocolWebhookDelivery(deliveries)
+
+	identifierNext := ""
+
+	if len(deliveries) == request.Limit {
+		nextQuery := c.Request.URL.Query()
+		nextQuery.Set("last_identifier", strconv.FormatInt(deliveries[len(deliveries)-1].ID, 10))
+
+		identifierNext = getRequestURL(c, nextQuery.Encode())
+	}
+
+	serializer.JSON(c, http.StatusOK, protocol.File{
+		DateUpdated:    dateUpdated,
+		Identifier:     getRequestURL(c, c.Request.URL.RawQuery),
+		IdentifierNext: identifierNext,
+		Total:          total,
+		List:           deliveryList,
+	})
+}
+
+// TestWebhookHandlerFunc sends a ping to the webhook right away and returns the result,
+// test deliveries are not retried.
+func TestWebhookHandlerFunc(c *gin.Context) {
+	webhookModel, errType, err := getWebhook(c)
+	if err != nil {
+		api.SetError(c, errType, err)
+
+		return
+	}
+
+	payload, err := json.Marshal(protocol.WebhookPing{
+		WebhookID:   webhookModel.ID.String(),
+		DateCreated: timex.Time(time.Now()),
+	})
+	if err != nil {
+		api.SetError(c, api.ErrorUnknown, err)
+
+		return
+	}
+
+	delivery := model.WebhookDelivery{
+		WebhookID: webhookModel.ID,
+		Event:     webhook.EventPing,
+		Payload:   payload,
+		Status:    webhook.StatusPending,
+		// Keep it away from the dispatcher while it is being sent
+		NextAttemptAt: time.Now().Add(time.Hour),
+	}
+
+	if err := database.DB.Create(&delivery).Error; err != nil {
+		api.SetError(c, api.ErrorDatabase, err)
+
+		return
+	}
+
+	if err := dispatcher.Deliver(c.Request.Context(), &delivery, 1); err != nil {
+		api.SetError(c, api.ErrorDatabase, err)
+
+		return
+	}
+
+	deliveryList, _ := service.FormatProtocolWebhookDelivery([]model.WebhookDelivery{delivery})
+
+	c.JSON(http.StatusOK, deliveryList[0])
+}
+
+// getWebhook finds the webhook of the path, the secret must be given as a bearer token.
+func getWebhook(c *gin.Context) (*model.Webhook, error, error) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, api.ErrorInvalidParams, err
+	}
+
+	webhookModel := model.Webhook{}
+	if err := database.DB.Where("id = ?", id).First(&webhookModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, api.ErrorNotFound, err
+		}
+
+		return nil, api.ErrorDatabase, err
+	}
+
+	secret := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(webhookModel.Secret)) != 1 {
+		return nil, api.ErrorNotFound, gorm.ErrRecordNotFound
+	}
+
+	return &webhookModel, nil, nil
+}
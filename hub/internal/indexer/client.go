@@ -2,118 +2,136 @@ package indexer
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
-	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
-	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/cache"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/config"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/metrics"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
-	"github.com/go-redis/redis/v8"
 	"github.com/go-resty/resty/v2"
-	"golang.org/x/sync/errgroup"
 )
 
-func GetItems(requestURL string, instance rss3uri.Instance, latest bool) error {
-	lockerKey := fmt.Sprintf("hub %s", requestURL)
+const (
+	// PollInterval is how often the job of a latest request is checked
+	PollInterval = 500 * time.Millisecond
+	// WaitTimeout bounds how long a latest request waits for its job, the job goes on after it
+	WaitTimeout = 30 * time.Second
 
-	if _, err := cache.GetRaw(context.Background(), lockerKey); err != nil && errors.Is(err, redis.Nil) {
-		if err = cache.SetRaw(context.Background(), lockerKey, time.Now().String(), time.Second*10); err != nil {
-			return err
-		}
+	// PriorityInteractive jobs take the rate limits of the upstream APIs ahead of the background ones
+	PriorityInteractive = "interactive"
+	PriorityBackground  = "background"
+)
+
+var client = resty.New()
+
+// GetItems queues a crawl of the instance on the indexer, which returns the job being run if the instance
+// is already being crawled. It waits for the job if latest is set until ctx is done or WaitTimeout has passed.
+func GetItems(ctx context.Context, instance rss3uri.Instance, latest bool) error {
+	start := time.Now()
 
+	job, err := enqueueJob(ctx, instance, latest)
+	if err != nil {
 		if latest {
-			return getItems(instance)
+			return err
 		}
 
-		go func() {
-			if err := getItems(instance); err != nil {
-				logger.Error(err)
-			}
-		}()
+		logger.Errorf("enqueue job of %s error: %v", instance, err)
+
+		return nil
 	}
 
-	return nil
-}
+	if !latest {
+		return nil
+	}
+
+	err = waitJob(ctx, job)
+
+	metrics.ObserveCrawl(start, latest, err)
 
-func getItems(instance rss3uri.Instance) error {
-	eg := errgroup.Group{}
-
-	for _, networkID := range constants.GetNetworkList(constants.PlatformIDEthereum) {
-		networkID := networkID
-		client := resty.New()
-
-		eg.Go(func() error {
-			return getItem(client, model.Account{
-				Identity:        strings.ToLower(instance.GetIdentity()),
-				Platform:        constants.PlatformSymbol(instance.GetSuffix()).ID().Int(),
-				ProfileID:       strings.ToLower(instance.GetIdentity()),
-				ProfilePlatform: constants.PlatformSymbol(instance.GetSuffix()).ID().Int(),
-				Source:          int(constants.NetworkIDCrossbell),
-			}, networkID)
-		})
+	// The notes crawled so far are still worth returning
+	if err != nil {
+		logger.Errorf("wait for job %s of %s error: %v", job.ID, instance, err)
 	}
 
-	return eg.Wait()
+	return nil
 }
 
-func getItem(client *resty.Client, account model.Account, networkID constants.NetworkID) error {
-	// Get the timestamp of the latest data to avoid duplicate pulls whenever possible.
-	var timestamp time.Time
-
-	if err := database.DB.
-		Raw(
-			`WITH "timestamp" AS (SELECT date_created AS "timestamp"
-                     FROM note4
-                     WHERE owner = ?
-                     ORDER BY date_created DESC
-                     LIMIT 1)
-SELECT COALESCE("timestamp".timestamp, TIMESTAMPTZ 'epoch') AS "timestamp"
-FROM "timestamp";`,
-			rss3uri.New(rss3uri.NewAccountInstance(account.Identity, constants.PlatformID(account.ProfilePlatform).Symbol())).String(),
-		).
-		Scan(&timestamp).
-		Error; err != nil {
-		return err
+// enqueueJob queues the job ahead of the background crawls if latest is set, as the request waits for it
+func enqueueJob(ctx context.Context, instance rss3uri.Instance, latest bool) (*Job, error) {
+	identity := strings.ToLower(instance.GetIdentity())
+	platformID := constants.PlatformSymbol(instance.GetSuffix()).ID().Int()
+
+	priority := PriorityBackground
+	if latest {
+		priority = PriorityInteractive
 	}
 
-	request := client.NewRequest()
-	params := map[string]string{
-		"proof":             strings.ToLower(account.Identity),
-		"platform_id":       strconv.Itoa(account.Platform),
-		"network_id":        strconv.Itoa(int(networkID)),
-		"profile_source_id": strconv.Itoa(account.Source),
-		"owner_id":          strings.ToLower(account.ProfileID),
-		"owner_platform_id": strconv.Itoa(account.ProfilePlatform),
-		// "timestamp":         big.NewInt(timestamp.Unix()).String(),
+	result := JobResponse{}
+
+	response, err := client.R().
+		SetContext(ctx).
+		SetQueryParams(map[string]string{
+			"proof":             identity,
+			"platform_id":       strconv.Itoa(platformID),
+			"profile_source_id": strconv.Itoa(int(constants.NetworkIDCrossbell)),
+			"owner_id":          identity,
+			"owner_platform_id": strconv.Itoa(platformID),
+			"priority":          priority,
+		}).
+		SetResult(&result).
+		Post(fmt.Sprintf("%s/jobs", config.Config.Hub.IndexerEndpoint))
+	if err != nil {
+		return nil, err
 	}
 
-	logger.Infof("%+v", params)
+	return result.job(response)
+}
 
-	result := Response{}
+func waitJob(ctx context.Context, job *Job) error {
+	ctx, cancel := context.WithTimeout(ctx, WaitTimeout)
+	defer cancel()
 
-	response, err := request.
-		SetQueryParams(params).
-		SetResult(&result).
-		Get(fmt.Sprintf("%s/item", config.Config.Hub.IndexerEndpoint))
-	if err != nil {
-		logger.Error(err)
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
 
-		return nil
-	}
+	for !job.Finished() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
 
-	if response.StatusCode() != http.StatusOK || result.Error.Code != 0 {
-		logger.Error(response.StatusCode(), result.Error.Code, result.Error.Msg)
+		result := JobResponse{}
 
-		return nil
+		response, err := client.R().
+			SetContext(ctx).
+			SetResult(&result).
+			Get(fmt.Sprintf("%s/jobs/%s", config.Config.Hub.IndexerEndpoint, job.ID))
+		if err != nil {
+			return err
+		}
+
+		if job, err = result.job(response); err != nil {
+			return err
+		}
+	}
+
+	if job.Status != JobStatusSucceeded {
+		return fmt.Errorf("job %s", job.Status)
 	}
 
 	return nil
 }
+
+func (r *JobResponse) job(response *resty.Response) (*Job, error) {
+	if response.StatusCode() != http.StatusOK || r.Error.Code != 0 || r.Job == nil {
+		return nil, fmt.Errorf("indexer error: status %d, code %d, %s", response.StatusCode(), r.Error.Code, r.Error.Msg)
+	}
+
+	return r.Job, nil
+}
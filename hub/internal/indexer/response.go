@@ -6,3 +6,34 @@ type Response struct {
 		Msg  string `json:"msg"`
 	} `json:"error"`
 }
+
+type JobStatus string
+
+const (
+	JobStatusQueued    JobStatus = "queued"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusSucceeded JobStatus = "succeeded"
+	JobStatusFailed    JobStatus = "failed"
+	JobStatusRetrying  JobStatus = "retrying"
+	JobStatusDead      JobStatus = "dead"
+)
+
+type Job struct {
+	ID     string    `json:"id"`
+	Status JobStatus `json:"status"`
+}
+
+// Finished tells whether an attempt has finished, the hub does not wait for the retries of a job.
+func (j *Job) Finished() bool {
+	switch j.Status {
+	case JobStatusSucceeded, JobStatusFailed, JobStatusRetrying, JobStatusDead:
+		return true
+	default:
+		return false
+	}
+}
+
+type JobResponse struct {
+	Response
+	Job *Job `json:"job"`
+}
@@ -1,16 +1,20 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/api"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/ens"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
 	"github.com/gin-gonic/gin"
 )
 
 const (
 	KeyInstance = "instance"
+	KeyName     = "name"
 )
 
 type InstanceUri struct {
@@ -37,10 +41,38 @@ func Instance() gin.HandlerFunc {
 			return
 		}
 
+		// Names are replaced by their address, which is what the items are owned by
+		if platformInstance, ok := instance.(*rss3uri.PlatformInstance); ok &&
+			platformInstance.Platform == constants.PlatformSymbolEthereum && ens.IsName(platformInstance.Identity) {
+			address, err := ens.Resolve(c.Request.Context(), platformInstance.Identity)
+			if err != nil {
+				if errors.Is(err, ens.ErrNotFound) {
+					api.SetError(c, api.ErrorNotFound, err)
+				} else {
+					api.SetError(c, api.ErrorDatabase, err)
+				}
+
+				c.Abort()
+
+				return
+			}
+
+			c.Set(KeyName, platformInstance.Identity)
+
+			platformInstance.Identity = address
+		}
+
 		c.Set(KeyInstance, instance)
 	}
 }
 
+// GetName returns the ENS name of the instance if it was requested by name.
+func GetName(c *gin.Context) (string, bool) {
+	name := c.GetString(KeyName)
+
+	return name, name != ""
+}
+
 func GetInstance(c *gin.Context) (rss3uri.Instance, error) {
 	value, exists := c.Get(KeyInstance)
 	if !exists {
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/api"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/apikey"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/apikey/keystore"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/ratelimit"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/config"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	KeyAPIKey = "api_key"
+
+	maxPeekBody = 1 << 20
+)
+
+// CrawlFunc tells whether a request may trigger crawls of the indexer.
+type CrawlFunc func(c *gin.Context) bool
+
+// FirstPage crawls on the first page of a list, the indexer is only asked for it.
+func FirstPage(c *gin.Context) bool {
+	return c.Query("cursor") == ""
+}
+
+// FirstBatchPage is FirstPage for batch requests with a JSON body.
+func FirstBatchPage(c *gin.Context) bool {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPeekBody))
+	if err != nil {
+		return true
+	}
+
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+
+	request := struct {
+		Cursor string `json:"cursor"`
+	}{}
+
+	// Invalid bodies are rejected by the handler, count them as crawls in the meantime
+	if err := json.Unmarshal(body, &request); err != nil {
+		return true
+	}
+
+	return request.Cursor == ""
+}
+
+// RateLimit authenticates the API key of a request and takes a token from its bucket,
+// requests for which crawl returns true have their own bucket. Requests without a key
+// are limited by IP address unless keys are required.
+func RateLimit(crawl CrawlFunc) gin.HandlerFunc {
+	return rateLimit(crawl, config.Config.Hub.APIKey.Required)
+}
+
+// PublicRateLimit never requires a key, it is meant for federation endpoints called by other servers.
+func PublicRateLimit(crawl CrawlFunc) gin.HandlerFunc {
+	return rateLimit(crawl, false)
+}
+
+func rateLimit(crawl CrawlFunc, required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		class, limit := apikey.ClassRead, config.Config.Hub.APIKey.ReadLimit
+		if crawl != nil && crawl(c) {
+			class, limit = apikey.ClassCrawl, config.Config.Hub.APIKey.CrawlLimit
+		}
+
+		subject := "ip:" + c.ClientIP()
+
+		var keyModel *model.APIKey
+
+		if key := c.GetHeader(apikey.Header); key != "" {
+			var err error
+
+			if keyModel, err = keystore.Find(key); err != nil {
+				if errors.Is(err, keystore.ErrInvalidKey) {
+					abort(c, http.StatusUnauthorized, api.ErrorUnauthorized, err)
+				} else {
+					abort(c, http.StatusInternalServerError, api.ErrorDatabase, err)
+				}
+
+				return
+			}
+
+			subject = "key:" + keyModel.ID.String()
+
+			if class == apikey.ClassCrawl && keyModel.CrawlLimit > 0 {
+				limit = keyModel.CrawlLimit
+			} else if class == apikey.ClassRead && keyModel.ReadLimit > 0 {
+				limit = keyModel.ReadLimit
+			}
+
+			c.Set(KeyAPIKey, keyModel)
+		} else if required {
+			abort(c, http.StatusUnauthorized, api.ErrorUnauthorized, errors.New("missing "+apikey.Header))
+
+			return
+		}
+
+		if limit <= 0 {
+			return
+		}
+
+		// Fail open, an unavailable Redis shouldn't take the hub down
+		result, err := ratelimit.Take(c.Request.Context(), subject, class, limit)
+		if err != nil {
+			logger.Errorf("take rate limit token of %s: %v", subject, err)
+
+			return
+		}
+
+		if keyModel != nil {
+			if err := keystore.CountUsage(c.Request.Context(), keyModel.ID, class, !result.Allowed); err != nil {
+				logger.Errorf("count usage of %s: %v", subject, err)
+			}
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+			abort(c, http.StatusTooManyRequests, api.ErrorTooManyRequests, errors.New(class+" rate limit exceeded"))
+		}
+	}
+}
+
+// abort keeps the status code, the Wrapper renders the error.
+func abort(c *gin.Context, status int, errType error, err error) {
+	c.Status(status)
+	api.SetError(c, errType, err)
+	c.Abort()
+}
@@ -16,7 +16,13 @@ func Wrapper() gin.HandlerFunc {
 		c.Next()
 
 		if err := c.Errors.Last(); err != nil {
-			c.AbortWithStatusJSON(http.StatusOK, &WrapperResponse{
+			// Errors are reported with 200 unless a handler set an error status
+			status := http.StatusOK
+			if c.Writer.Status() >= http.StatusBadRequest {
+				status = c.Writer.Status()
+			}
+
+			c.AbortWithStatusJSON(status, &WrapperResponse{
 				Code:  c.GetInt("code"),
 				Error: err.Error(),
 			})
@@ -1,16 +1,26 @@
 package model
 
-import "github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
+import (
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
+)
 
 type BatchGetNodeListRequest struct {
-	AddressList    []string `json:"addresses"`       // account address list
-	Limit          int      `json:"limit"`           // amount of data per page
-	LastIdentifier string   `json:"last_identifier"` // the last identifier of the previous page
-	Tags           []string `json:"tags"`
-	ExcludeTags    []string `json:"exclude_tags"`
-	ItemSources    []string `json:"item_sources"`
-	Networks       []string `json:"networks"`
-	Latest         bool     `json:"latest"`
+	AddressList []string  `json:"addresses"` // account address list
+	Limit       int       `json:"limit"`     // amount of data per page
+	Cursor      string    `json:"cursor"`    // the identifier_next of the previous page
+	Tags        []string  `json:"tags"`
+	ExcludeTags []string  `json:"exclude_tags"`
+	ItemSources []string  `json:"item_sources"`
+	Networks    []string  `json:"networks"`
+	MimeTypes   []string  `json:"mime_types"` // attachment mime types
+	DateFrom    time.Time `json:"date_from"`  // inclusive
+	DateTo      time.Time `json:"date_to"`    // exclusive
+	Latest      bool      `json:"latest"`
+
+	LastIdentifier string `json:"last_identifier"` // replaced by Cursor, only bound to be rejected
 
 	InstanceList []rss3uri.Instance `json:"-"` // parsed from address list
+	FollowedBy   string             `json:"-"` // replaces the instance list by the accounts the owner follows
 }
@@ -0,0 +1,140 @@
+// Package netguard keeps the requests to URLs given by clients, such as webhooks and the actors
+// of ActivityPub requests, away from the internal network of the hub.
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrForbiddenAddress = errors.New("forbidden address")
+	ErrInvalidURL       = errors.New("invalid url")
+)
+
+// forbiddenNetworks are the reserved ranges the net package has no helper for
+var forbiddenNetworks = mustParseCIDRs(
+	"0.0.0.0/8",
+	"100.64.0.0/10",
+	"192.0.0.0/24",
+	"198.18.0.0/15",
+	"240.0.0.0/4",
+	"64:ff9b::/96",
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+
+		networks = append(networks, network)
+	}
+
+	return networks
+}
+
+// IsForbidden tells whether the address is loopback, private, link-local, e.g. 169.254.169.254, or reserved.
+func IsForbidden(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return true
+	}
+
+	for _, network := range forbiddenNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Control rejects the connections to forbidden addresses. It runs on the resolved address
+// right before connecting, so a host resolving to another address later is checked as well.
+func Control(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip == nil || IsForbidden(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+
+	return nil
+}
+
+// NewClient returns a client which does not follow redirects, and does not connect to forbidden
+// addresses unless allowPrivate is set. Proxies are not used, they would connect on its behalf.
+func NewClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	if !allowPrivate {
+		dialer.Control = Control
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// CheckURL parses a URL given by a client and checks its scheme. Unless allowPrivate is set,
+// its host must not resolve to a forbidden address, which fails early what Control would reject.
+func CheckURL(ctx context.Context, rawURL string, allowPrivate bool, schemes ...string) (*url.URL, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || parsedURL.Hostname() == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidURL, rawURL)
+	}
+
+	allowed := false
+
+	for _, scheme := range schemes {
+		if parsedURL.Scheme == scheme {
+			allowed = true
+		}
+	}
+
+	if !allowed {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidURL, rawURL)
+	}
+
+	if allowPrivate {
+		return parsedURL, nil
+	}
+
+	addresses, err := net.DefaultResolver.LookupIPAddr(ctx, parsedURL.Hostname())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidURL, err)
+	}
+
+	for _, address := range addresses {
+		if IsForbidden(address.IP) {
+			return nil, fmt.Errorf("%w: %s", ErrForbiddenAddress, address.IP)
+		}
+	}
+
+	return parsedURL, nil
+}
@@ -0,0 +1,86 @@
+package netguard_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/netguard"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsForbidden(t *testing.T) {
+	for address, forbidden := range map[string]bool{
+		"127.0.0.1":        true,
+		"10.0.0.1":         true,
+		"172.16.0.1":       true,
+		"192.168.1.1":      true,
+		"169.254.169.254":  true,
+		"100.64.0.1":       true,
+		"0.0.0.0":          true,
+		"::1":              true,
+		"fe80::1":          true,
+		"fd00::1":          true,
+		"::ffff:127.0.0.1": true,
+		"1.1.1.1":          false,
+		"2606:4700::1111":  false,
+	} {
+		assert.Equal(t, forbidden, netguard.IsForbidden(net.ParseIP(address)), address)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/", http.StatusFound)
+
+			return
+		}
+
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	// The server listens on a loopback address
+	_, err := netguard.NewClient(time.Second, false).Get(server.URL)
+	assert.True(t, errors.Is(err, netguard.ErrForbiddenAddress))
+
+	client := netguard.NewClient(time.Second, true)
+
+	response, err := client.Get(server.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	_ = response.Body.Close()
+
+	response, err = client.Get(server.URL + "/redirect")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusFound, response.StatusCode)
+	_ = response.Body.Close()
+}
+
+func TestCheckURL(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := netguard.CheckURL(ctx, "ftp://1.1.1.1/", false, "http", "https")
+	assert.ErrorIs(t, err, netguard.ErrInvalidURL)
+
+	_, err = netguard.CheckURL(ctx, "https:///path", false, "http", "https")
+	assert.ErrorIs(t, err, netguard.ErrInvalidURL)
+
+	_, err = netguard.CheckURL(ctx, "http://127.0.0.1:8080/", false, "http", "https")
+	assert.ErrorIs(t, err, netguard.ErrForbiddenAddress)
+
+	_, err = netguard.CheckURL(ctx, "http://169.254.169.254/latest/meta-data", false, "http", "https")
+	assert.ErrorIs(t, err, netguard.ErrForbiddenAddress)
+
+	_, err = netguard.CheckURL(ctx, "http://127.0.0.1:8080/", true, "http", "https")
+	assert.Nil(t, err)
+
+	parsedURL, err := netguard.CheckURL(ctx, "https://1.1.1.1/hook", false, "https")
+	assert.Nil(t, err)
+	assert.Equal(t, "/hook", parsedURL.Path)
+}
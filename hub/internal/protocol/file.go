@@ -24,6 +24,13 @@ type File struct {
 	DateUpdated    *timex.Time `json:"date_updated,omitempty"`
 	Identifier     string      `json:"identifier,omitempty"`
 	IdentifierNext string      `json:"identifier_next,omitempty"`
+	Resolved       *Resolved   `json:"resolved,omitempty"`
 	Total          int64       `json:"total"`
 	List           any         `json:"list"`
 }
+
+// Resolved is the name an account was requested by and the address it resolved to.
+type Resolved struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
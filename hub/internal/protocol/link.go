@@ -0,0 +1,15 @@
+package protocol
+
+import (
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/timex"
+)
+
+type Link struct {
+	Type        string                 `json:"type"`
+	From        string                 `json:"from"`
+	To          string                 `json:"to"`
+	DateCreated timex.Time             `json:"date_created"`
+	DateUpdated timex.Time             `json:"date_updated"`
+	Source      string                 `json:"source"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+}
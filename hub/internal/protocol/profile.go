@@ -0,0 +1,17 @@
+package protocol
+
+import (
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/timex"
+)
+
+type Profile struct {
+	Identifier  string                 `json:"identifier"`
+	DateCreated timex.Time             `json:"date_created"`
+	DateUpdated timex.Time             `json:"date_updated"`
+	Name        string                 `json:"name,omitempty"`
+	Avatars     []string               `json:"avatars,omitempty"`
+	Bio         string                 `json:"bio,omitempty"`
+	Attachments []ItemAttachment       `json:"attachments,omitempty"`
+	Source      string                 `json:"source"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+}
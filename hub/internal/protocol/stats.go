@@ -0,0 +1,24 @@
+package protocol
+
+import (
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/timex"
+)
+
+type Stats struct {
+	Version    version   `json:"version"`
+	Identifier string    `json:"identifier"`
+	Resolved   *Resolved `json:"resolved,omitempty"`
+	Notes      ItemStats `json:"notes"`
+	Assets     ItemStats `json:"assets"`
+}
+
+// ItemStats counts items by group, months are formatted as YYYY-MM in UTC.
+type ItemStats struct {
+	Total     int64            `json:"total"`
+	DateFirst *timex.Time      `json:"date_first,omitempty"`
+	DateLast  *timex.Time      `json:"date_last,omitempty"`
+	Tags      map[string]int64 `json:"tags"`
+	Networks  map[string]int64 `json:"networks"`
+	Sources   map[string]int64 `json:"sources"`
+	Months    map[string]int64 `json:"months"`
+}
@@ -0,0 +1,60 @@
+// Package v050 is the v0.5.0 shape of the protocol, which replaces the free-form
+// metadata of items with typed actions. It is converted from the v0.4.0 shape, so
+// handlers keep producing protocol types and older clients keep their responses.
+package v050
+
+import (
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/timex"
+)
+
+const Version = "v0.5.0"
+
+type File struct {
+	Version        string             `json:"version"`
+	DateUpdated    *timex.Time        `json:"date_updated,omitempty"`
+	Identifier     string             `json:"identifier,omitempty"`
+	IdentifierNext string             `json:"identifier_next,omitempty"`
+	Resolved       *protocol.Resolved `json:"resolved,omitempty"`
+	Total          int64              `json:"total"`
+	List           any                `json:"list"`
+}
+
+// Stats only differs from v0.4.0 by its version.
+type Stats struct {
+	protocol.Stats
+
+	Version string `json:"version"`
+}
+
+// FormatFile converts items of the list, other lists are kept as they are.
+func FormatFile(file protocol.File) File {
+	list := file.List
+
+	if itemList, ok := file.List.([]protocol.Item); ok {
+		formattedItemList := make([]Item, 0, len(itemList))
+
+		for _, item := range itemList {
+			formattedItemList = append(formattedItemList, FormatItem(item))
+		}
+
+		list = formattedItemList
+	}
+
+	return File{
+		Version:        Version,
+		DateUpdated:    file.DateUpdated,
+		Identifier:     file.Identifier,
+		IdentifierNext: file.IdentifierNext,
+		Resolved:       file.Resolved,
+		Total:          file.Total,
+		List:           list,
+	}
+}
+
+func FormatStats(stats protocol.Stats) Stats {
+	return Stats{
+		Stats:   stats,
+		Version: Version,
+	}
+}
@@ -0,0 +1,206 @@
+package v050
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/timex"
+)
+
+const (
+	ActionTypeTransfer = "transfer"
+	ActionTypeMint     = "mint"
+	ActionTypeBurn     = "burn"
+	ActionTypeDonate   = "donate"
+	ActionTypePost     = "post"
+)
+
+type Item struct {
+	Identifier  string                    `json:"identifier"`
+	DateCreated timex.Time                `json:"date_created"`
+	DateUpdated timex.Time                `json:"date_updated"`
+	RelatedURLs []string                  `json:"related_urls"`
+	Links       string                    `json:"links"`
+	BackLinks   string                    `json:"backlinks"`
+	Tags        []string                  `json:"tags"`
+	Authors     []string                  `json:"authors"`
+	Title       string                    `json:"title,omitempty"`
+	Summary     string                    `json:"summary,omitempty"`
+	Attachments []protocol.ItemAttachment `json:"attachments"`
+	Source      string                    `json:"source"`
+	Actions     []Action                  `json:"actions"`
+	Metadata    Metadata                  `json:"metadata"`
+}
+
+// Action is what happened in a note, assets have none.
+type Action struct {
+	Type   string `json:"type"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+	Token  *Token `json:"token,omitempty"`
+	Amount string `json:"amount,omitempty"`
+}
+
+type Token struct {
+	Standard string `json:"standard,omitempty"`
+	Address  string `json:"address,omitempty"`
+	ID       string `json:"id,omitempty"`
+	Symbol   string `json:"symbol,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Decimals int    `json:"decimals,omitempty"`
+}
+
+// Metadata keeps the fields of v0.4.0 metadata which have no typed counterpart in Extra.
+type Metadata struct {
+	Network         string         `json:"network"`
+	Proof           string         `json:"proof"`
+	TransactionHash string         `json:"transaction_hash,omitempty"`
+	Token           *Token         `json:"token,omitempty"` // the token of an asset
+	Extra           map[string]any `json:"extra,omitempty"`
+}
+
+// typedMetadataKeys are moved out of Extra into typed fields
+var typedMetadataKeys = []string{
+	"network", "proof", "transaction_hash", "from", "to", "amount", "decimal", "destination",
+	"token_standard", "contract_type", "token_id", "token_symbol", "token_address", "collection_address", "collection_name",
+}
+
+func FormatItem(item protocol.Item) Item {
+	// The metadata is copied as its typed keys are deleted below
+	metadata := make(metadataMap, len(item.Metadata))
+	for key, value := range item.Metadata {
+		metadata[key] = value
+	}
+
+	formattedItem := Item{
+		Identifier:  item.Identifier,
+		DateCreated: item.DateCreated,
+		DateUpdated: item.DateUpdated,
+		RelatedURLs: nonNil(item.RelatedURLs),
+		Links:       item.Links,
+		BackLinks:   item.BackLinks,
+		Tags:        nonNil(item.Tags),
+		Authors:     nonNil(item.Authors),
+		Title:       item.Title,
+		Summary:     item.Summary,
+		Attachments: item.Attachments,
+		Source:      item.Source,
+		Actions:     make([]Action, 0, 1),
+		Metadata: Metadata{
+			Network:         metadata.string("network"),
+			Proof:           metadata.string("proof"),
+			TransactionHash: metadata.string("transaction_hash"),
+		},
+	}
+
+	if formattedItem.Attachments == nil {
+		formattedItem.Attachments = make([]protocol.ItemAttachment, 0)
+	}
+
+	token := metadata.token()
+
+	switch {
+	case strings.HasPrefix(item.Identifier, fmt.Sprintf("rss3://%s:", constants.PrefixNameAsset)):
+		formattedItem.Metadata.Token = token
+	case item.Source == constants.NoteSourceNameMirrorEntry.String(), item.Source == constants.NoteSourceNameCrossbell.String():
+		formattedItem.Actions = append(formattedItem.Actions, Action{
+			Type: ActionTypePost,
+		})
+	case item.Source == constants.NoteSourceNameGitcoinContribution.String():
+		formattedItem.Actions = append(formattedItem.Actions, Action{
+			Type:   ActionTypeDonate,
+			From:   metadata.string("from"),
+			To:     metadata.string("destination"),
+			Token:  token,
+			Amount: metadata.string("amount"),
+		})
+	case token != nil:
+		formattedItem.Actions = append(formattedItem.Actions, Action{
+			Type:   transferType(metadata.string("from"), metadata.string("to")),
+			From:   metadata.string("from"),
+			To:     metadata.string("to"),
+			Token:  token,
+			Amount: metadata.string("amount"),
+		})
+	}
+
+	for _, key := range typedMetadataKeys {
+		delete(metadata, key)
+	}
+
+	if len(metadata) > 0 {
+		formattedItem.Metadata.Extra = metadata
+	}
+
+	return formattedItem
+}
+
+// transferType tells mints and burns apart, they move tokens from or to the zero address
+func transferType(from, to string) string {
+	switch {
+	case isZeroAddress(from):
+		return ActionTypeMint
+	case isZeroAddress(to):
+		return ActionTypeBurn
+	default:
+		return ActionTypeTransfer
+	}
+}
+
+func isZeroAddress(address string) bool {
+	return strings.HasPrefix(address, "0x") && strings.Trim(address[2:], "0") == ""
+}
+
+type metadataMap map[string]any
+
+// string formats numbers too, crawlers aren't consistent in their types
+func (m metadataMap) string(key string) string {
+	switch value := m[key].(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func (m metadataMap) token() *Token {
+	token := Token{
+		Standard: m.string("token_standard"),
+		ID:       m.string("token_id"),
+		Symbol:   m.string("token_symbol"),
+		Name:     m.string("collection_name"),
+	}
+
+	if token.Standard == "" {
+		token.Standard = m.string("contract_type")
+	}
+
+	if token.Address = m.string("token_address"); token.Address == "" {
+		token.Address = m.string("collection_address")
+	}
+
+	if decimals, err := strconv.Atoi(m.string("decimal")); err == nil {
+		token.Decimals = decimals
+	}
+
+	if token == (Token{}) {
+		return nil
+	}
+
+	return &token
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return make([]string, 0)
+	}
+
+	return values
+}
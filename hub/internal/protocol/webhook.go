@@ -0,0 +1,36 @@
+package protocol
+
+import (
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/timex"
+)
+
+type Webhook struct {
+	ID          string     `json:"id"`
+	URL         string     `json:"url"`
+	Secret      string     `json:"secret,omitempty"`
+	Instances   []string   `json:"instances"`
+	Tags        []string   `json:"tags,omitempty"`
+	Networks    []string   `json:"networks,omitempty"`
+	ItemSources []string   `json:"item_sources,omitempty"`
+	DateCreated timex.Time `json:"date_created"`
+}
+
+type WebhookDelivery struct {
+	ID                 int64      `json:"id"`
+	Event              string     `json:"event"`
+	NoteIdentifier     string     `json:"note_identifier,omitempty"`
+	Status             string     `json:"status"`
+	Attempts           int        `json:"attempts"`
+	NextAttemptAt      timex.Time `json:"next_attempt_at"`
+	ResponseStatusCode int        `json:"response_status_code,omitempty"`
+	ResponseLatency    int64      `json:"response_latency,omitempty"`
+	Error              string     `json:"error,omitempty"`
+	DateCreated        timex.Time `json:"date_created"`
+	DateUpdated        timex.Time `json:"date_updated"`
+}
+
+// WebhookPing is the payload of test deliveries.
+type WebhookPing struct {
+	WebhookID   string     `json:"webhook_id"`
+	DateCreated timex.Time `json:"date_created"`
+}
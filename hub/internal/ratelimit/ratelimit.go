@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/cache"
+	"github.com/go-redis/redis/v8"
+)
+
+const keyPrefix = "hub:ratelimit"
+
+// tokenBucket refills the bucket for the elapsed time, then takes a token if there is one.
+// It returns whether a token was taken, the tokens left and the milliseconds until the next token.
+var tokenBucket = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "timestamp")
+local tokens = tonumber(bucket[1]) or capacity
+local timestamp = tonumber(bucket[2]) or now
+
+tokens = math.min(capacity, tokens + math.max(0, now - timestamp) * rate)
+
+local allowed = 0
+local wait = 0
+
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) / rate)
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "timestamp", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil(capacity / rate))
+
+return {allowed, math.floor(tokens), wait}
+`)
+
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Take takes a token from the bucket of the subject and class,
+// which holds limit tokens and refills them over a minute.
+func Take(ctx context.Context, subject string, class string, limit int) (*Result, error) {
+	rate := float64(limit) / float64(time.Minute.Milliseconds())
+
+	values, err := tokenBucket.Run(
+		ctx,
+		cache.GetRedisClient(),
+		[]string{cache.ConstructKey(keyPrefix, class, subject)},
+		limit, rate, time.Now().UnixMilli(),
+	).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Allowed:    values[0] == 1,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,145 @@
+package responsecache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/stream"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/cache"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	keyPrefix           = "hub:response"
+	generationKeyPrefix = "hub:generation"
+
+	// Expiration bounds the staleness if a notification was missed
+	Expiration = 5 * time.Minute
+	// generationExpiration outlives the responses of a generation
+	generationExpiration = 2 * Expiration
+
+	invalidateTimeout = 5 * time.Second
+)
+
+// Setup invalidates the responses of the owners whose notes or assets are written.
+func Setup() {
+	invalidate := func(owners []string) {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), invalidateTimeout)
+			defer cancel()
+
+			if err := Invalidate(ctx, owners); err != nil {
+				logger.Errorf("invalidate responses of %v: %v", owners, err)
+			}
+		}()
+	}
+
+	stream.Watch(invalidate)
+	stream.WatchAssets(invalidate)
+}
+
+// Key returns the cache key of a request, it changes whenever the notes or assets of the owner are written.
+// The request must be normalized, e.g. with sorted filters.
+func Key(ctx context.Context, owner string, kind string, request any) (string, error) {
+	generation, err := Generation(ctx, owner)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+
+	for _, value := range []any{owner, generation, kind, string(data)} {
+		if err := json.NewEncoder(hash).Encode(value); err != nil {
+			return "", err
+		}
+	}
+
+	return cache.ConstructKey(keyPrefix, hex.EncodeToString(hash.Sum(nil))), nil
+}
+
+// Generation returns the generation of the responses of the owners, or only the global one if there are none.
+// It changes exactly when they are invalidated, and is never reused, e.g. to build ETags.
+func Generation(ctx context.Context, owners ...string) (string, error) {
+	keys := []string{generationKeyPrefix}
+
+	for _, owner := range owners {
+		keys = append(keys, generationKey(owner))
+	}
+
+	pipeline := cache.GetRedisClient().Pipeline()
+
+	// An expired generation starts again from a new value rather than from nil
+	for _, key := range keys {
+		pipeline.SetNX(ctx, key, uuid.NewString(), generationExpiration)
+	}
+
+	generations := pipeline.MGet(ctx, keys...)
+
+	if _, err := pipeline.Exec(ctx); err != nil {
+		return "", err
+	}
+
+	values := make([]string, 0, len(keys))
+
+	for _, generation := range generations.Val() {
+		value, _ := generation.(string)
+		values = append(values, value)
+	}
+
+	return strings.Join(values, ","), nil
+}
+
+// Get reports whether the response was found.
+func Get(ctx context.Context, key string, response any) (bool, error) {
+	if err := cache.Get(ctx, key, response); err != nil {
+		if errors.Is(err, cache.CacheMissedError) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+func Set(ctx context.Context, key string, response any) error {
+	return cache.Set(ctx, key, response, Expiration)
+}
+
+// Invalidate moves on the generation of the owners, or of all owners if there are none.
+func Invalidate(ctx context.Context, owners []string) error {
+	pipeline := cache.GetRedisClient().Pipeline()
+
+	keys := []string{generationKeyPrefix}
+
+	if len(owners) > 0 {
+		keys = make([]string, 0, len(owners))
+
+		for _, owner := range owners {
+			keys = append(keys, generationKey(owner))
+		}
+	}
+
+	for _, key := range keys {
+		pipeline.Set(ctx, key, uuid.NewString(), generationExpiration)
+	}
+
+	_, err := pipeline.Exec(ctx)
+
+	return err
+}
+
+func generationKey(owner string) string {
+	return cache.ConstructKey(generationKeyPrefix, owner)
+}
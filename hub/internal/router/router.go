@@ -5,14 +5,20 @@ import (
 	"time"
 
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/api"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/feed"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/handler"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/middleware"
-	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/serializer"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/cache"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/config"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/health"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/metrics"
 	"github.com/getsentry/sentry-go"
 	sentrygin "github.com/getsentry/sentry-go/gin"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func Initialize() *gin.Engine {
@@ -23,6 +29,11 @@ func Initialize() *gin.Engine {
 		router = gin.New()
 	}
 
+	// The rate limits are keyed by the client address, which must not come from a header anyone can set
+	if err := router.SetTrustedProxies(config.Config.Hub.Server.TrustedProxies); err != nil {
+		panic(err)
+	}
+
 	if config.Config.Sentry.DSN != "" {
 		if err := sentry.Init(sentry.ClientOptions{
 			Dsn:        config.Config.Sentry.DSN,
@@ -42,26 +53,92 @@ func Initialize() *gin.Engine {
 		MaxAge:           12 * time.Hour,
 	}))
 
+	// Metrics observe the status and errors set by the wrapper
+	router.Use(metrics.Middleware())
+
 	// Response wrapper
 	router.Use(middleware.Wrapper())
 
 	router.NoRoute(api.NoRouterHandlerFunc)
 	router.NoMethod(api.NoMethodHandlerFunc)
 	router.GET("/", api.GetIndexHandlerFunc)
+	router.GET("/metrics", metrics.Handler())
+	router.GET("/healthz", gin.WrapH(health.LivenessHandler()))
+	router.GET("/readyz", gin.WrapH(health.ReadinessHandler(
+		health.Postgres(func() *gorm.DB { return database.DB }),
+		health.Redis(cache.GetRedisClient),
+		// Only reported, most requests are served from the database while the indexer is down
+		health.Optional(health.HTTP("indexer", fmt.Sprintf("%s/healthz", config.Config.Hub.IndexerEndpoint))),
+	)))
+	router.GET("/.well-known/webfinger", middleware.PublicRateLimit(nil), handler.GetWebFingerHandlerFunc)
 
-	// Latest version API
-	apiRouter := router.Group(fmt.Sprintf("/%s", protocol.Version))
-	{
-		instanceMiddleware := middleware.Instance()
+	// Versions are served side by side
+	for _, version := range versions {
+		version.register(router.Group(fmt.Sprintf("/%s", version.serializer.Version()), serializer.Use(version.serializer)))
+	}
 
-		apiRouter.Use(middleware.ListLimit())
+	return router
+}
 
-		apiRouter.GET("/:instance/assets", instanceMiddleware, handler.GetAssetListHandlerFunc)
-		apiRouter.GET("/:instance/notes", instanceMiddleware, handler.GetNoteListHandlerFunc)
-		apiRouter.POST("/notes", handler.BatchGetNoteListHandlerFunc)
-	}
+// versions register their own routes, the responses of handlers are converted by their serializer.
+// v0.4.0 is frozen, changes of the shape of items go into the latest version.
+var versions = []struct {
+	serializer serializer.Serializer
+	register   func(apiRouter *gin.RouterGroup)
+}{
+	{serializer.V040, registerV040},
+	{serializer.V050, registerV050},
+}
 
-	// Older version API
+// registerItemRoutes registers the routes whose responses depend on the version
+func registerItemRoutes(apiRouter *gin.RouterGroup) {
+	instanceMiddleware := middleware.Instance()
 
-	return router
+	// Requests of the first page might crawl, the others are read from the database
+	crawlLimit := middleware.RateLimit(middleware.FirstPage)
+	readLimit := middleware.RateLimit(nil)
+
+	apiRouter.GET("/:instance", readLimit, instanceMiddleware, handler.GetItemHandlerFunc)
+	apiRouter.GET("/:instance/assets", crawlLimit, instanceMiddleware, handler.GetAssetListHandlerFunc)
+	apiRouter.GET("/:instance/notes", crawlLimit, instanceMiddleware, handler.GetNoteListHandlerFunc)
+	apiRouter.GET("/:instance/notes.rss", crawlLimit, instanceMiddleware, handler.GetNoteFeedHandlerFunc(feed.FormatRSS))
+	apiRouter.GET("/:instance/notes.atom", crawlLimit, instanceMiddleware, handler.GetNoteFeedHandlerFunc(feed.FormatAtom))
+	apiRouter.GET("/:instance/notes.json", crawlLimit, instanceMiddleware, handler.GetNoteFeedHandlerFunc(feed.FormatJSON))
+	apiRouter.GET("/:instance/stats", crawlLimit, instanceMiddleware, handler.GetStatsHandlerFunc)
+	apiRouter.GET("/:instance/timeline", readLimit, instanceMiddleware, handler.GetTimelineHandlerFunc)
+	apiRouter.GET("/:instance/profiles", readLimit, instanceMiddleware, handler.GetProfileListHandlerFunc)
+	apiRouter.GET("/:instance/links", readLimit, instanceMiddleware, handler.GetLinkListHandlerFunc)
+	apiRouter.GET("/:instance/backlinks", readLimit, instanceMiddleware, handler.GetBackLinkListHandlerFunc)
+	apiRouter.POST("/notes", middleware.RateLimit(middleware.FirstBatchPage), handler.BatchGetNoteListHandlerFunc)
+	apiRouter.POST("/assets", middleware.RateLimit(middleware.FirstBatchPage), handler.BatchGetAssetListHandlerFunc)
+	apiRouter.GET("/notes/stream", readLimit, handler.GetNoteStreamHandlerFunc)
+	apiRouter.GET("/search", readLimit, handler.SearchHandlerFunc)
+}
+
+// registerV040 also serves ActivityPub, webhooks and GraphQL, which have their own formats
+func registerV040(apiRouter *gin.RouterGroup) {
+	apiRouter.Use(middleware.ListLimit())
+
+	registerItemRoutes(apiRouter)
+
+	instanceMiddleware := middleware.Instance()
+	readLimit := middleware.RateLimit(nil)
+
+	apiRouter.GET("/:instance/actor", middleware.PublicRateLimit(nil), instanceMiddleware, handler.GetActorHandlerFunc)
+	apiRouter.GET("/:instance/outbox", middleware.PublicRateLimit(middleware.FirstPage), instanceMiddleware, handler.GetOutboxHandlerFunc)
+	apiRouter.POST("/:instance/inbox", middleware.PublicRateLimit(nil), instanceMiddleware, handler.PostInboxHandlerFunc)
+	apiRouter.POST("/webhooks", readLimit, handler.CreateWebhookHandlerFunc)
+	apiRouter.GET("/webhooks/:id", readLimit, handler.GetWebhookHandlerFunc)
+	apiRouter.DELETE("/webhooks/:id", readLimit, handler.DeleteWebhookHandlerFunc)
+	apiRouter.GET("/webhooks/:id/deliveries", readLimit, handler.GetWebhookDeliveryListHandlerFunc)
+	apiRouter.POST("/webhooks/:id/test", readLimit, handler.TestWebhookHandlerFunc)
+	apiRouter.GET("/graphql", readLimit, handler.GraphQLHandlerFunc)
+	apiRouter.POST("/graphql", readLimit, handler.GraphQLHandlerFunc)
+}
+
+// registerV050 serves items with typed actions
+func registerV050(apiRouter *gin.RouterGroup) {
+	apiRouter.Use(middleware.ListLimit())
+
+	registerItemRoutes(apiRouter)
 }
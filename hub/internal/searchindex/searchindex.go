@@ -0,0 +1,193 @@
+// Package searchindex indexes the notes and assets for search once their writes are committed,
+// following their updated_at from a cursor in the database, so that rolled back rows are never searchable
+// and the rows written while the search backend is unavailable are indexed once it is back.
+package searchindex
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/stream"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/search"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	batchLimit = 500
+
+	// lookback re-scans recently updated rows, since concurrent transactions
+	// may commit in a different order than their updated_at
+	lookback = 10 * time.Second
+	// interval polls the assets, which are not notified, and the notes in case a notification was lost
+	interval = 15 * time.Second
+)
+
+// Kinds are the kinds of documents, in the order they are indexed.
+var Kinds = []string{search.KindNote, search.KindAsset}
+
+// position is the place of a row in the order of updated_at and identifier
+type position struct {
+	UpdatedAt  time.Time
+	Identifier string
+}
+
+func (p position) after(other position) bool {
+	return p.UpdatedAt.After(other.UpdatedAt) || (p.UpdatedAt.Equal(other.UpdatedAt) && p.Identifier > other.Identifier)
+}
+
+// Run indexes the rows updated since the cursors until the context is done,
+// it is safe to run on several hubs at once.
+func Run(ctx context.Context) {
+	if !search.Enabled() {
+		return
+	}
+
+	subscription := stream.Subscribe(nil)
+	defer stream.Unsubscribe(subscription)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-subscription.C:
+			syncKind(ctx, search.KindNote)
+		case <-ticker.C:
+			for _, kind := range Kinds {
+				syncKind(ctx, kind)
+			}
+		}
+	}
+}
+
+func syncKind(ctx context.Context, kind string) {
+	if err := Sync(ctx, kind); err != nil {
+		logger.Errorf("index %s documents: %v", kind, err)
+	}
+}
+
+// Sync indexes the rows of a kind updated since its cursor, a new cursor starts from now,
+// the older rows are indexed by Reindex.
+func Sync(ctx context.Context, kind string) error {
+	searchCursor := model.SearchCursor{Kind: kind, CursorUpdatedAt: time.Now()}
+
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&searchCursor).Error; err != nil {
+		return err
+	}
+
+	if err := database.DB.Where("kind = ?", kind).First(&searchCursor).Error; err != nil {
+		return err
+	}
+
+	cursor := position{UpdatedAt: searchCursor.CursorUpdatedAt, Identifier: searchCursor.CursorIdentifier}
+	backlog := false
+
+	for {
+		internalDB := database.DB
+
+		if backlog {
+			internalDB = internalDB.Where("(updated_at, identifier) > (?, ?)", cursor.UpdatedAt, cursor.Identifier)
+		} else {
+			internalDB = internalDB.Where("updated_at > ?", cursor.UpdatedAt.Add(-lookback))
+		}
+
+		count, last, err := index(ctx, kind, internalDB)
+		if err != nil {
+			return err
+		}
+
+		if count == 0 {
+			return nil
+		}
+
+		if last.after(cursor) {
+			cursor = last
+
+			// Other hubs may have moved the cursor further meanwhile
+			if err := database.DB.Model(&model.SearchCursor{}).
+				Where("kind = ?", kind).
+				Where("(cursor_updated_at, cursor_identifier) < (?, ?)", cursor.UpdatedAt, cursor.Identifier).
+				Updates(map[string]any{
+					"cursor_updated_at": cursor.UpdatedAt,
+					"cursor_identifier": cursor.Identifier,
+				}).Error; err != nil {
+				return err
+			}
+		}
+
+		if backlog = count == batchLimit; !backlog {
+			return nil
+		}
+	}
+}
+
+// Reindex indexes all the rows of a kind updated since the given time, e.g. to backfill a new index,
+// without moving the cursor.
+func Reindex(ctx context.Context, kind string, since time.Time) (int64, error) {
+	var total int64
+
+	cursor := position{UpdatedAt: since}
+
+	for {
+		count, last, err := index(ctx, kind, database.DB.Where("(updated_at, identifier) > (?, ?)", cursor.UpdatedAt, cursor.Identifier))
+		if err != nil {
+			return total, err
+		}
+
+		total += int64(count)
+		cursor = last
+
+		if count < batchLimit {
+			return total, nil
+		}
+
+		logger.Infof("indexed %d %s documents, up to %s", total, kind, cursor.UpdatedAt)
+	}
+}
+
+// index indexes a batch of the rows matched by internalDB, and returns their number and the position of the last one.
+func index(ctx context.Context, kind string, internalDB *gorm.DB) (int, position, error) {
+	internalDB = internalDB.Order("updated_at").Order("identifier").Limit(batchLimit)
+
+	var last position
+
+	documents := make([]search.Document, 0, batchLimit)
+
+	switch kind {
+	case search.KindNote:
+		notes := make([]model.Note, 0, batchLimit)
+		if err := internalDB.Find(&notes).Error; err != nil {
+			return 0, last, err
+		}
+
+		for _, note := range notes {
+			documents = append(documents, search.NoteDocument(note))
+			last = position{UpdatedAt: note.UpdatedAt, Identifier: note.Identifier}
+		}
+	case search.KindAsset:
+		assets := make([]model.Asset, 0, batchLimit)
+		if err := internalDB.Find(&assets).Error; err != nil {
+			return 0, last, err
+		}
+
+		for _, asset := range assets {
+			documents = append(documents, search.AssetDocument(asset))
+			last = position{UpdatedAt: asset.UpdatedAt, Identifier: asset.Identifier}
+		}
+	default:
+		return 0, last, fmt.Errorf("unknown kind %s", kind)
+	}
+
+	if err := search.Index(ctx, documents); err != nil {
+		return 0, last, err
+	}
+
+	return len(documents), last, nil
+}
@@ -0,0 +1,77 @@
+package serializer
+
+import (
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol/v050"
+	"github.com/gin-gonic/gin"
+)
+
+const KeySerializer = "serializer"
+
+// Serializer converts the protocol types built by handlers into the shape of a version,
+// other values are rendered as they are.
+type Serializer interface {
+	Version() string
+	Serialize(value any) any
+}
+
+// The versions served are listed by the router
+var (
+	V040 Serializer = v040Serializer{}
+	V050 Serializer = v050Serializer{}
+)
+
+// Use sets the serializer of the requests of a version.
+func Use(serializer Serializer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(KeySerializer, serializer)
+	}
+}
+
+// Get defaults to v0.4.0 for routes outside of a version.
+func Get(c *gin.Context) Serializer {
+	if value, exists := c.Get(KeySerializer); exists {
+		if serializer, ok := value.(Serializer); ok {
+			return serializer
+		}
+	}
+
+	return V040
+}
+
+func JSON(c *gin.Context, code int, value any) {
+	c.JSON(code, Get(c).Serialize(value))
+}
+
+type v040Serializer struct{}
+
+func (v040Serializer) Version() string {
+	return protocol.Version
+}
+
+func (v040Serializer) Serialize(value any) any {
+	return value
+}
+
+type v050Serializer struct{}
+
+func (v050Serializer) Version() string {
+	return v050.Version
+}
+
+func (v050Serializer) Serialize(value any) any {
+	switch value := value.(type) {
+	case protocol.File:
+		return v050.FormatFile(value)
+	case *protocol.File:
+		return v050.FormatFile(*value)
+	case protocol.Item:
+		return v050.FormatItem(value)
+	case *protocol.Item:
+		return v050.FormatItem(*value)
+	case protocol.Stats:
+		return v050.FormatStats(value)
+	default:
+		return value
+	}
+}
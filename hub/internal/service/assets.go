@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/api"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/dao"
+	m "github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/timex"
+)
+
+// BatchGetAssetList queries the assets of the addresses, see batchGetItemList.
+func BatchGetAssetList(ctx context.Context, req m.BatchGetNodeListRequest) (protocol.File, error, error) {
+	return batchGetItemList(ctx, req, dao.BatchGetAssetList, FormatProtocolItemByAsset, dao.AssetCursor)
+}
+
+// FormatProtocolItemByAsset format data
+func FormatProtocolItemByAsset(assetList []model.Asset) ([]protocol.Item, *timex.Time, error, error) {
+	var dateUpdated *timex.Time
+
+	var itemList = make([]protocol.Item, 0, len(assetList))
+
+	for _, asset := range assetList {
+		attachmentList := make([]protocol.ItemAttachment, 0)
+		if err := json.Unmarshal(asset.Attachments, &attachmentList); err != nil {
+			return nil, nil, api.ErrorIndexer, err
+		}
+
+		updated := timex.Time(asset.DateUpdated)
+		if dateUpdated == nil || dateUpdated.Time().Before(asset.DateUpdated) {
+			dateUpdated = &updated
+		}
+
+		metadata := make(map[string]interface{})
+		if err := json.Unmarshal(asset.Metadata, &metadata); err != nil {
+			return nil, nil, api.ErrorIndexer, err
+		}
+
+		metadata["network"] = asset.MetadataNetwork
+		metadata["proof"] = asset.MetadataProof
+
+		itemList = append(itemList, protocol.Item{
+			Identifier:  asset.Identifier,
+			DateCreated: timex.Time(asset.DateCreated),
+			DateUpdated: timex.Time(asset.DateUpdated),
+			RelatedURLs: asset.RelatedURLs,
+			Links:       fmt.Sprintf("%s/links", asset.Identifier),
+			BackLinks:   fmt.Sprintf("%s/backlinks", asset.Identifier),
+			Tags:        asset.Tags,
+			Authors:     asset.Authors,
+			Title:       asset.Title,
+			Summary:     asset.Summary,
+			Attachments: attachmentList,
+			Source:      asset.Source,
+			Metadata:    metadata,
+		})
+	}
+
+	return itemList, dateUpdated, nil, nil
+}
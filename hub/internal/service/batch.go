@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/api"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/cursor"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/indexer"
+	m "github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/timex"
+)
+
+// MaxBatchAddresses bounds the addresses of a batch request, each of them is crawled on the first page
+const MaxBatchAddresses = 100
+
+var ErrTooManyAddresses = fmt.Errorf("at most %d addresses", MaxBatchAddresses)
+
+// batchGetItemList
+// parse the address list into instance list
+// query database with getList
+// format data with format, the cursor of the next page is the one of the last item
+func batchGetItemList[T any](
+	ctx context.Context,
+	req m.BatchGetNodeListRequest,
+	getList func(req m.BatchGetNodeListRequest) ([]T, int64, error),
+	format func(list []T) ([]protocol.Item, *timex.Time, error, error),
+	getCursor func(item T) (string, error),
+) (protocol.File, error, error) {
+	emptyFile := protocol.File{
+		List: make([]protocol.Item, 0),
+	}
+
+	if len(req.AddressList) > MaxBatchAddresses {
+		return emptyFile, api.ErrorInvalidParams, ErrTooManyAddresses
+	}
+
+	req.InstanceList = []rss3uri.Instance{}
+	for _, address := range req.AddressList {
+		uri, err := rss3uri.Parse(address)
+		if err != nil {
+			continue
+		}
+
+		req.InstanceList = append(req.InstanceList, uri.Instance)
+
+		// get item
+		if len(req.Cursor) == 0 {
+			if err := indexer.GetItems(ctx, uri.Instance, req.Latest); err != nil {
+				return emptyFile, api.ErrorIndexer, err
+			}
+		}
+	}
+
+	if len(req.InstanceList) == 0 {
+		return emptyFile, nil, nil
+	}
+
+	list, total, err := getList(req)
+	if err != nil {
+		if errors.Is(err, cursor.ErrInvalidCursor) {
+			return emptyFile, api.ErrorInvalidParams, err
+		}
+
+		return emptyFile, api.ErrorDatabase, err
+	}
+
+	itemList, dateUpdated, errType, err := format(list)
+	if err != nil {
+		return emptyFile, errType, err
+	}
+
+	identifierNext := ""
+	if total > int64(req.Limit) {
+		if identifierNext, err = getCursor(list[len(list)-1]); err != nil {
+			return emptyFile, api.ErrorUnknown, err
+		}
+	}
+
+	file := protocol.File{
+		DateUpdated:    dateUpdated,
+		Total:          total,
+		List:           itemList,
+		IdentifierNext: identifierNext,
+	}
+
+	return file, nil, nil
+}
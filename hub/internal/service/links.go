@@ -0,0 +1,44 @@
+package service
+
+import (
+	"encoding/json"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/api"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/timex"
+)
+
+// FormatProtocolLink format data
+func FormatProtocolLink(linkList []model.Link) ([]protocol.Link, *timex.Time, error, error) {
+	var dateUpdated *timex.Time
+
+	var result = make([]protocol.Link, 0, len(linkList))
+
+	for _, link := range linkList {
+		updated := timex.Time(link.DateUpdated)
+		if dateUpdated == nil || dateUpdated.Time().Before(link.DateUpdated) {
+			dateUpdated = &updated
+		}
+
+		metadata := make(map[string]interface{})
+		if len(link.Metadata) > 0 {
+			if err := json.Unmarshal(link.Metadata, &metadata); err != nil {
+				return nil, nil, api.ErrorIndexer, err
+			}
+		}
+
+		result = append(result, protocol.Link{
+			Type:        constants.LinkTypeID(link.Type).Name().String(),
+			From:        link.Origin,
+			To:          link.Target,
+			DateCreated: timex.Time(link.DateCreated),
+			DateUpdated: timex.Time(link.DateUpdated),
+			Source:      constants.LinkSourceID(link.Source).Name().String(),
+			Metadata:    metadata,
+		})
+	}
+
+	return result, dateUpdated, nil, nil
+}
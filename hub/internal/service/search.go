@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/api"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/dao"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/search"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/timex"
+)
+
+// Search returns the matching items in the order of the search backend, along with the total of matches.
+// Items deleted from the database since they were indexed are left out.
+func Search(ctx context.Context, query search.Query) ([]protocol.Item, *timex.Time, int64, error, error) {
+	result, err := search.Search(ctx, query)
+	if err != nil {
+		return nil, nil, 0, api.ErrorSearch, err
+	}
+
+	noteIdentifiers, assetIdentifiers := make([]string, 0), make([]string, 0)
+
+	for _, hit := range result.Hits {
+		switch hit.Kind {
+		case search.KindNote:
+			noteIdentifiers = append(noteIdentifiers, hit.Identifier)
+		case search.KindAsset:
+			assetIdentifiers = append(assetIdentifiers, hit.Identifier)
+		}
+	}
+
+	noteList, err := dao.GetNoteListByIdentifiers(noteIdentifiers)
+	if err != nil {
+		return nil, nil, 0, api.ErrorDatabase, err
+	}
+
+	assetList, err := dao.GetAssetListByIdentifiers(assetIdentifiers)
+	if err != nil {
+		return nil, nil, 0, api.ErrorDatabase, err
+	}
+
+	noteItemList, noteDateUpdated, errType, err := FormatProtocolItemByNote(noteList)
+	if err != nil {
+		return nil, nil, 0, errType, err
+	}
+
+	assetItemList, assetDateUpdated, errType, err := FormatProtocolItemByAsset(assetList)
+	if err != nil {
+		return nil, nil, 0, errType, err
+	}
+
+	itemMap := make(map[string]protocol.Item, len(noteItemList)+len(assetItemList))
+
+	for _, item := range append(noteItemList, assetItemList...) {
+		itemMap[item.Identifier] = item
+	}
+
+	itemList := make([]protocol.Item, 0, len(result.Hits))
+
+	for _, hit := range result.Hits {
+		if item, exists := itemMap[hit.Identifier]; exists {
+			itemList = append(itemList, item)
+		}
+	}
+
+	dateUpdated := noteDateUpdated
+	if dateUpdated == nil || (assetDateUpdated != nil && dateUpdated.Time().Before(assetDateUpdated.Time())) {
+		dateUpdated = assetDateUpdated
+	}
+
+	return itemList, dateUpdated, result.Total, nil, nil
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/protocol"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/timex"
+)
+
+// FormatProtocolWebhook format data, the secret is only included on creation
+func FormatProtocolWebhook(webhook model.Webhook, withSecret bool) protocol.Webhook {
+	instances := make([]string, 0, len(webhook.Owners))
+
+	for _, owner := range webhook.Owners {
+		uri, err := rss3uri.Parse(owner)
+		if err != nil {
+			continue
+		}
+
+		instances = append(instances, uri.Instance.String())
+	}
+
+	result := protocol.Webhook{
+		ID:          webhook.ID.String(),
+		URL:         webhook.URL,
+		Instances:   instances,
+		Tags:        webhook.Tags,
+		Networks:    webhook.Networks,
+		ItemSources: webhook.Sources,
+		DateCreated: timex.Time(webhook.CreatedAt),
+	}
+
+	if withSecret {
+		result.Secret = webhook.Secret
+	}
+
+	return result
+}
+
+// FormatProtocolWebhookDelivery format data
+func FormatProtocolWebhookDelivery(deliveryList []model.WebhookDelivery) ([]protocol.WebhookDelivery, *timex.Time) {
+	var dateUpdated *timex.Time
+
+	var result = make([]protocol.WebhookDelivery, 0, len(deliveryList))
+
+	for _, delivery := range deliveryList {
+		updated := timex.Time(delivery.UpdatedAt)
+		if dateUpdated == nil || dateUpdated.Time().Before(delivery.UpdatedAt) {
+			dateUpdated = &updated
+		}
+
+		result = append(result, protocol.WebhookDelivery{
+			ID:                 delivery.ID,
+			Event:              delivery.Event,
+			NoteIdentifier:     delivery.NoteIdentifier.String,
+			Status:             delivery.Status,
+			Attempts:           delivery.Attempts,
+			NextAttemptAt:      timex.Time(delivery.NextAttemptAt),
+			ResponseStatusCode: delivery.ResponseStatusCode,
+			ResponseLatency:    delivery.ResponseLatency,
+			Error:              delivery.Error,
+			DateCreated:        timex.Time(delivery.CreatedAt),
+			DateUpdated:        updated,
+		})
+	}
+
+	return result, dateUpdated
+}
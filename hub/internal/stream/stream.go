@@ -0,0 +1,169 @@
+package stream
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/config"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
+	"github.com/lib/pq"
+)
+
+const (
+	minReconnectInterval = 10 * time.Second
+	maxReconnectInterval = time.Minute
+)
+
+var (
+	defaultBroker = NewBroker()
+	// assetBroker is only watched, the streams are about notes
+	assetBroker = NewBroker()
+)
+
+// Broker fans out the notifications of a channel, e.g. database.NoteChannel, to the subscribers of their owners.
+type Broker struct {
+	mutex       sync.RWMutex
+	subscribers map[*Subscription]struct{}
+	watchers    []func(owners []string)
+}
+
+// Subscription receives a signal on C whenever one of its owners has new notes,
+// or any owner if it has none. Signals are coalesced so a slow reader only sees one pending.
+type Subscription struct {
+	C      chan struct{}
+	owners map[string]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: map[*Subscription]struct{}{},
+	}
+}
+
+func (b *Broker) Subscribe(owners []string) *Subscription {
+	subscription := &Subscription{
+		C:      make(chan struct{}, 1),
+		owners: map[string]struct{}{},
+	}
+
+	for _, owner := range owners {
+		subscription.owners[strings.ToLower(owner)] = struct{}{}
+	}
+
+	b.mutex.Lock()
+	b.subscribers[subscription] = struct{}{}
+	b.mutex.Unlock()
+
+	return subscription
+}
+
+func (b *Broker) Unsubscribe(subscription *Subscription) {
+	b.mutex.Lock()
+	delete(b.subscribers, subscription)
+	b.mutex.Unlock()
+}
+
+// Watch calls fn with the owners of every notification, or nil if any owner might have new notes.
+// It runs on the publishing goroutine, so it must not block.
+func (b *Broker) Watch(fn func(owners []string)) {
+	b.mutex.Lock()
+	b.watchers = append(b.watchers, fn)
+	b.mutex.Unlock()
+}
+
+// Publish signals the subscribers of the comma separated owners, or all subscribers if it is empty.
+func (b *Broker) Publish(payload string) {
+	var ownerList []string
+
+	owners := map[string]struct{}{}
+
+	if payload != "" {
+		ownerList = strings.Split(payload, ",")
+
+		for _, owner := range ownerList {
+			owners[owner] = struct{}{}
+		}
+	}
+
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for _, watcher := range b.watchers {
+		watcher(ownerList)
+	}
+
+	for subscription := range b.subscribers {
+		if len(owners) > 0 && len(subscription.owners) > 0 && !subscription.matches(owners) {
+			continue
+		}
+
+		select {
+		case subscription.C <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (s *Subscription) matches(owners map[string]struct{}) bool {
+	for owner := range owners {
+		if _, exists := s.owners[owner]; exists {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Setup listens to database.NoteChannel and database.AssetChannel in the background.
+func Setup() error {
+	listener := pq.NewListener(config.Config.Postgres.DSN, minReconnectInterval, maxReconnectInterval, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Errorf("note listener event %d: %v", event, err)
+		}
+	})
+
+	for _, channel := range []string{database.NoteChannel, database.AssetChannel} {
+		if err := listener.Listen(channel); err != nil {
+			return err
+		}
+	}
+
+	go func() {
+		for notification := range listener.Notify {
+			// A nil notification follows a reconnection, notifications might have been lost meanwhile
+			if notification == nil {
+				defaultBroker.Publish("")
+				assetBroker.Publish("")
+
+				continue
+			}
+
+			if notification.Channel == database.AssetChannel {
+				assetBroker.Publish(notification.Extra)
+			} else {
+				defaultBroker.Publish(notification.Extra)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func Subscribe(owners []string) *Subscription {
+	return defaultBroker.Subscribe(owners)
+}
+
+func Unsubscribe(subscription *Subscription) {
+	defaultBroker.Unsubscribe(subscription)
+}
+
+func Watch(fn func(owners []string)) {
+	defaultBroker.Watch(fn)
+}
+
+// WatchAssets is Watch for the notifications of database.AssetChannel.
+func WatchAssets(fn func(owners []string)) {
+	assetBroker.Watch(fn)
+}
@@ -0,0 +1,42 @@
+package stream
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrInvalidToken = errors.New("invalid resume token")
+
+// Token is the position of a stream, the last sent note ordered by created_at and identifier.
+type Token struct {
+	CreatedAt  time.Time
+	Identifier string
+}
+
+func (t Token) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(t.CreatedAt.UTC().Format(time.RFC3339Nano) + "," + t.Identifier))
+}
+
+func ParseToken(value string) (*Token, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	rawCreatedAt, identifier, found := strings.Cut(string(data), ",")
+	if !found {
+		return nil, ErrInvalidToken
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, rawCreatedAt)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return &Token{
+		CreatedAt:  createdAt,
+		Identifier: identifier,
+	}, nil
+}
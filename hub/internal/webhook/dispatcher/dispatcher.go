@@ -0,0 +1,295 @@
+package dispatcher
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/netguard"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/service"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/stream"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/webhook"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/config"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	enqueueBatchLimit = 1000
+	deliverBatchLimit = 10
+
+	// enqueueLookback re-scans recently inserted notes, since concurrent transactions
+	// may commit in a different order than their created_at
+	enqueueLookback = 10 * time.Second
+	// enqueueInterval polls in case a notification was lost
+	enqueueInterval = 15 * time.Second
+	deliverInterval = time.Second
+	// deliveryLease hides a claimed delivery from other hubs until it is sent
+	deliveryLease = time.Minute
+)
+
+var client = netguard.NewClient(10*time.Second, config.Config.Hub.Webhook.AllowPrivateNetworks)
+
+// Run enqueues the new notes of webhook owners and sends due deliveries until the context is done,
+// it is safe to run on several hubs at once.
+func Run(ctx context.Context) {
+	subscription := stream.Subscribe(nil)
+	defer stream.Unsubscribe(subscription)
+
+	enqueueTicker := time.NewTicker(enqueueInterval)
+	defer enqueueTicker.Stop()
+
+	deliverTicker := time.NewTicker(deliverInterval)
+	defer deliverTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-subscription.C:
+			enqueue()
+		case <-enqueueTicker.C:
+			enqueue()
+		case <-deliverTicker.C:
+			if err := DeliverDue(ctx); err != nil {
+				logger.Errorf("deliver webhooks: %v", err)
+			}
+		}
+	}
+}
+
+func enqueue() {
+	webhooks := make([]model.Webhook, 0)
+	if err := database.DB.Find(&webhooks).Error; err != nil {
+		logger.Errorf("find webhooks: %v", err)
+
+		return
+	}
+
+	for _, webhookModel := range webhooks {
+		if err := Enqueue(webhookModel); err != nil {
+			logger.Errorf("enqueue webhook %s: %v", webhookModel.ID, err)
+		}
+	}
+}
+
+// Enqueue creates the deliveries of the notes inserted since the webhook cursor,
+// a note is only enqueued once per webhook.
+func Enqueue(webhookModel model.Webhook) error {
+	backlog := false
+
+	for {
+		internalDB := database.DB.Where("owner IN ?", []string(webhookModel.Owners))
+
+		if backlog {
+			internalDB = internalDB.Where("(created_at, identifier) > (?, ?)", webhookModel.CursorCreatedAt, webhookModel.CursorIdentifier)
+		} else {
+			internalDB = internalDB.Where("created_at > ?", webhookModel.CursorCreatedAt.Add(-enqueueLookback))
+		}
+
+		if len(webhookModel.Tags) > 0 {
+			internalDB = internalDB.Where("tags && ?", webhookModel.Tags)
+		}
+
+		if len(webhookModel.Networks) > 0 {
+			internalDB = internalDB.Where("metadata_network IN ?", []string(webhookModel.Networks))
+		}
+
+		if len(webhookModel.Sources) > 0 {
+			internalDB = internalDB.Where("source IN ?", []string(webhookModel.Sources))
+		}
+
+		notes := make([]model.Note, 0)
+		if err := internalDB.
+			Order("created_at").
+			Order("identifier").
+			Limit(enqueueBatchLimit).
+			Find(&notes).Error; err != nil {
+			return err
+		}
+
+		if len(notes) == 0 {
+			return nil
+		}
+
+		itemList, _, _, err := service.FormatProtocolItemByNote(notes)
+		if err != nil {
+			return err
+		}
+
+		deliveries := make([]model.WebhookDelivery, 0, len(notes))
+
+		for i, note := range notes {
+			payload, err := json.Marshal(itemList[i])
+			if err != nil {
+				return err
+			}
+
+			deliveries = append(deliveries, model.WebhookDelivery{
+				WebhookID:      webhookModel.ID,
+				Event:          webhook.EventNote,
+				NoteIdentifier: sql.NullString{String: note.Identifier, Valid: true},
+				Payload:        payload,
+				Status:         webhook.StatusPending,
+				NextAttemptAt:  time.Now(),
+			})
+
+			if note.CreatedAt.After(webhookModel.CursorCreatedAt) ||
+				(note.CreatedAt.Equal(webhookModel.CursorCreatedAt) && note.Identifier > webhookModel.CursorIdentifier) {
+				webhookModel.CursorCreatedAt, webhookModel.CursorIdentifier = note.CreatedAt, note.Identifier
+			}
+		}
+
+		if err := database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error; err != nil {
+				return err
+			}
+
+			// Other hubs may have moved the cursor further meanwhile
+			return tx.Model(&model.Webhook{}).
+				Where("id = ?", webhookModel.ID).
+				Where("(cursor_created_at, cursor_identifier) < (?, ?)", webhookModel.CursorCreatedAt, webhookModel.CursorIdentifier).
+				Updates(map[string]any{
+					"cursor_created_at": webhookModel.CursorCreatedAt,
+					"cursor_identifier": webhookModel.CursorIdentifier,
+				}).Error
+		}); err != nil {
+			return err
+		}
+
+		if backlog = len(notes) == enqueueBatchLimit; !backlog {
+			return nil
+		}
+	}
+}
+
+// DeliverDue claims and sends the pending deliveries whose next attempt is due.
+func DeliverDue(ctx context.Context) error {
+	for {
+		deliveries := make([]model.WebhookDelivery, 0, deliverBatchLimit)
+
+		if err := database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.
+				Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("status = ? AND next_attempt_at <= ?", webhook.StatusPending, time.Now()).
+				Order("next_attempt_at").
+				Limit(deliverBatchLimit).
+				Find(&deliveries).Error; err != nil {
+				return err
+			}
+
+			if len(deliveries) == 0 {
+				return nil
+			}
+
+			ids := make([]int64, 0, len(deliveries))
+			for _, delivery := range deliveries {
+				ids = append(ids, delivery.ID)
+			}
+
+			return tx.Model(&model.WebhookDelivery{}).
+				Where("id IN ?", ids).
+				Update("next_attempt_at", time.Now().Add(deliveryLease)).Error
+		}); err != nil {
+			return err
+		}
+
+		var waitGroup sync.WaitGroup
+
+		for i := range deliveries {
+			waitGroup.Add(1)
+
+			go func(delivery *model.WebhookDelivery) {
+				defer waitGroup.Done()
+
+				if err := Deliver(ctx, delivery, webhook.MaxAttempts); err != nil {
+					logger.Errorf("deliver webhook delivery %d: %v", delivery.ID, err)
+				}
+			}(&deliveries[i])
+		}
+
+		waitGroup.Wait()
+
+		if len(deliveries) < deliverBatchLimit {
+			return nil
+		}
+	}
+}
+
+// Deliver sends a delivery to its webhook and records the result,
+// failed deliveries are retried with backoff until maxAttempts.
+// The returned error is only about recording, the send error is kept in the delivery.
+func Deliver(ctx context.Context, delivery *model.WebhookDelivery, maxAttempts int) error {
+	webhookModel := model.Webhook{}
+
+	if err := database.DB.Where("id = ?", delivery.WebhookID).First(&webhookModel).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		// The webhook has been deleted
+		record(delivery, nil, err, 0)
+	} else {
+		result, err := webhook.Send(ctx, client, webhookModel.URL, webhookModel.Secret, delivery.ID, delivery.Event, delivery.Payload)
+		record(delivery, result, err, maxAttempts)
+	}
+
+	return database.DB.Select("*").Updates(delivery).Error
+}
+
+func record(delivery *model.WebhookDelivery, result *webhook.Result, err error, maxAttempts int) {
+	delivery.Attempts++
+	delivery.ResponseStatusCode = 0
+	delivery.ResponseLatency = 0
+	delivery.Error = ""
+
+	if result != nil {
+		delivery.ResponseStatusCode = result.StatusCode
+		delivery.ResponseLatency = result.Latency.Milliseconds()
+	}
+
+	switch {
+	case err == nil:
+		delivery.Status = webhook.StatusSucceeded
+	case delivery.Attempts >= maxAttempts:
+		delivery.Status = webhook.StatusFailed
+		delivery.Error = err.Error()
+	default:
+		delivery.Status = webhook.StatusPending
+		delivery.Error = err.Error()
+		delivery.NextAttemptAt = time.Now().Add(webhook.Backoff(delivery.Attempts))
+	}
+}
+
+// NewWebhook registers a webhook receiving the notes inserted from now on.
+func NewWebhook(url string, owners, tags, networks, sources []string) (*model.Webhook, error) {
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	webhookModel := model.Webhook{
+		ID:              uuid.New(),
+		URL:             url,
+		Secret:          secret,
+		Owners:          pq.StringArray(owners),
+		Tags:            pq.StringArray(tags),
+		Networks:        pq.StringArray(networks),
+		Sources:         pq.StringArray(sources),
+		CursorCreatedAt: time.Now(),
+	}
+
+	if err := database.DB.Create(&webhookModel).Error; err != nil {
+		return nil, err
+	}
+
+	return &webhookModel, nil
+}
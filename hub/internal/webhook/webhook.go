@@ -0,0 +1,117 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	EventNote = "note"
+	EventPing = "ping"
+
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+
+	HeaderSignature = "X-RSS3-Signature"
+	HeaderTimestamp = "X-RSS3-Timestamp"
+	HeaderEvent     = "X-RSS3-Event"
+	HeaderDelivery  = "X-RSS3-Delivery"
+
+	// MaxAttempts gives up after about four hours of retries, the backoffs of the nine retries add up to 4h15m30s
+	MaxAttempts = 10
+
+	minBackoff = 30 * time.Second
+	maxBackoff = 6 * time.Hour
+	// maxDrainedBody is read from a response so that the connection can be reused
+	maxDrainedBody = 4096
+)
+
+// Result never holds the response body, a receiver may be made to echo what it should not.
+type Result struct {
+	StatusCode int
+	Latency    time.Duration
+}
+
+func NewSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(secret), nil
+}
+
+// Sign returns the HMAC-SHA256 of "<timestamp>.<body>", receivers should compare it in constant time
+// and reject stale timestamps.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return fmt.Sprintf("sha256=%s", hex.EncodeToString(mac.Sum(nil)))
+}
+
+// Backoff doubles the delay after each failed attempt.
+func Backoff(attempts int) time.Duration {
+	backoff := minBackoff
+
+	for i := 1; i < attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > maxBackoff {
+		return maxBackoff
+	}
+
+	return backoff
+}
+
+// Send posts a signed payload, any status other than 2xx is an error.
+// The client should come from netguard.NewClient, the URL is given by the webhook owner.
+func Send(ctx context.Context, client *http.Client, url string, secret string, deliveryID int64, event string, payload []byte) (*Result, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := time.Now().Unix()
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderEvent, event)
+	request.Header.Set(HeaderDelivery, strconv.FormatInt(deliveryID, 10))
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, Sign(secret, timestamp, payload))
+
+	start := time.Now()
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	result := &Result{
+		StatusCode: response.StatusCode,
+		Latency:    time.Since(start),
+	}
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, maxDrainedBody))
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return result, fmt.Errorf("unexpected status %s", response.Status)
+	}
+
+	return result, nil
+}
@@ -1,14 +1,23 @@
 package main
 
 import (
+	"context"
 	"os"
 
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/activitypub/federation"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/dao"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/responsecache"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/router"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/searchindex"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/stream"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/webhook/dispatcher"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
 	_ "github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/cache" // will auto Setup by `init()`
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/config"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/search"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/web"
+	"github.com/spf13/cobra"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/jaeger"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
@@ -57,7 +66,26 @@ func init() {
 	))
 }
 
-func main() {
+func RunHTTPServer(cmd *cobra.Command, args []string) error {
+	if err := dao.CheckCursorSecret(); err != nil {
+		return err
+	}
+
+	responsecache.Setup()
+
+	// Searching is optional, the other endpoints are served without it
+	if err := search.Setup(); err != nil {
+		logger.Errorf("search is disabled: %v", err)
+	}
+
+	if err := stream.Setup(); err != nil {
+		return err
+	}
+
+	go dispatcher.Run(context.Background())
+	go federation.Run(context.Background())
+	go searchindex.Run(context.Background())
+
 	srv := &web.Server{
 		RunMode:      config.Config.Hub.Server.RunMode,
 		HttpPort:     config.Config.Hub.Server.HttpPort,
@@ -69,4 +97,21 @@ func main() {
 	defer logger.Logger.Sync()
 
 	srv.Start()
+
+	return nil
+}
+
+// rootCmd runs the server when no command is given
+var rootCmd = &cobra.Command{
+	Use:  "hub",
+	RunE: RunHTTPServer,
+}
+
+func main() {
+	rootCmd.AddCommand(apiKeyCmd)
+	rootCmd.AddCommand(searchCmd)
+
+	if err := rootCmd.Execute(); err != nil {
+		panic(err)
+	}
 }
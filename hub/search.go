@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/hub/internal/searchindex"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/search"
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search",
+	Short: "Manage the search index of the hub",
+}
+
+func init() {
+	reindexCmd := &cobra.Command{
+		Use:   "reindex",
+		Short: "Index the notes and assets updated since a date, e.g. to backfill a new index",
+		Args:  cobra.NoArgs,
+		RunE:  RunSearchReindex,
+	}
+
+	reindexCmd.Flags().String("kind", "", "note or asset, both if empty")
+	reindexCmd.Flags().String("since", "", "date of the oldest update to index, e.g. 2022-06-01, everything if empty")
+
+	searchCmd.AddCommand(reindexCmd)
+}
+
+func RunSearchReindex(cmd *cobra.Command, args []string) error {
+	kind, _ := cmd.Flags().GetString("kind")
+	rawSince, _ := cmd.Flags().GetString("since")
+
+	kinds := searchindex.Kinds
+	if kind != "" {
+		kinds = []string{kind}
+	}
+
+	var since time.Time
+
+	if rawSince != "" {
+		var err error
+
+		if since, err = time.Parse("2006-01-02", rawSince); err != nil {
+			return err
+		}
+	}
+
+	if err := search.Setup(); err != nil {
+		return err
+	}
+
+	if !search.Enabled() {
+		return search.ErrDisabled
+	}
+
+	for _, kind := range kinds {
+		total, err := searchindex.Reindex(context.Background(), kind, since)
+		if err != nil {
+			return fmt.Errorf("reindex %s documents after %d: %w", kind, total, err)
+		}
+
+		fmt.Printf("indexed %d %s documents\n", total, kind)
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/jobs"
+	"github.com/spf13/cobra"
+)
+
+// deadLetterErrorWidth keeps a row of the list on one line
+const deadLetterErrorWidth = 80
+
+func newDeadLettersCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "deadletters",
+		Short: "Manage the crawl jobs which are out of attempts",
+	}
+
+	var offset, limit int64
+
+	listCommand := &cobra.Command{
+		Use:   "list",
+		Short: "List the dead jobs, the latest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobList, total, err := jobs.DeadLetters(context.Background(), offset, limit)
+			if err != nil {
+				return err
+			}
+
+			writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+			fmt.Fprintln(writer, "ID\tDIED AT\tATTEMPTS\tCLASS\tPROOF\tERROR")
+
+			for _, job := range jobList {
+				var diedAt string
+				if job.FinishedAt != nil {
+					diedAt = job.FinishedAt.Format(time.RFC3339)
+				}
+
+				fmt.Fprintf(writer, "%s\t%s\t%d\t%s\t%s\t%s\n",
+					job.ID, diedAt, job.Attempts, job.Class, job.Param.Identity, firstError(job))
+			}
+
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+
+			fmt.Printf("%d of %d dead jobs\n", len(jobList), total)
+
+			return nil
+		},
+	}
+
+	listCommand.Flags().Int64Var(&offset, "offset", 0, "skip the latest dead jobs")
+	listCommand.Flags().Int64Var(&limit, "limit", 20, "the number of dead jobs to list")
+
+	inspectCommand := &cobra.Command{
+		Use:   "inspect <id>",
+		Short: "Print a job with the error of every network",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			job, err := jobs.Get(context.Background(), args[0])
+			if err != nil {
+				return err
+			}
+
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+
+			return encoder.Encode(job)
+		},
+	}
+
+	var all bool
+
+	requeueCommand := &cobra.Command{
+		Use:   "requeue [<id>...]",
+		Short: "Put dead jobs back in the queue with their attempts reset",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			ids := args
+
+			if all {
+				// The jobs are collected before any is requeued, as requeuing removes them from the dead letters
+				for offset := int64(0); ; offset += 100 {
+					jobList, _, err := jobs.DeadLetters(ctx, offset, 100)
+					if err != nil {
+						return err
+					}
+
+					if len(jobList) == 0 {
+						break
+					}
+
+					for _, job := range jobList {
+						ids = append(ids, job.ID)
+					}
+				}
+			}
+
+			if len(ids) == 0 {
+				return fmt.Errorf("no job to requeue, pass the IDs or --all")
+			}
+
+			for _, id := range ids {
+				if _, err := jobs.Requeue(ctx, id); err != nil {
+					return fmt.Errorf("requeue job %s: %w", id, err)
+				}
+
+				fmt.Printf("requeued %s\n", id)
+			}
+
+			return nil
+		},
+	}
+
+	requeueCommand.Flags().BoolVar(&all, "all", false, "requeue every dead job")
+
+	command.AddCommand(listCommand, inspectCommand, requeueCommand)
+
+	return command
+}
+
+func firstError(job *jobs.Job) string {
+	for _, result := range job.Networks {
+		if result.Error == "" {
+			continue
+		}
+
+		message := fmt.Sprintf("%s: %s", result.Network, result.Error)
+		if len(message) > deadLetterErrorWidth {
+			message = message[:deadLetterErrorWidth-3] + "..."
+		}
+
+		return message
+	}
+
+	return ""
+}
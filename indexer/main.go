@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"log"
+	"net/http"
 	_ "net/http/pprof"
 	"os"
 
@@ -10,13 +11,16 @@ import (
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/api/gitcoin"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/api/zksync"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/autoupdater"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/jobs"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/router"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/subscribe/ens"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/cache"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/config"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/health"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/web"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/cobra"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/jaeger"
@@ -24,6 +28,7 @@ import (
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"gorm.io/gorm"
 )
 
 func init() {
@@ -81,6 +86,14 @@ func RunHTTPServer(cmd *cobra.Command, args []string) error {
 
 	defer logger.Logger.Sync()
 
+	if workers := jobs.Workers(); workers > 0 {
+		go func() {
+			if err := jobs.NewPool(workers).Run(context.Background()); err != nil {
+				logger.Errorf("jobs pool error: %v", err)
+			}
+		}()
+	}
+
 	srv.Start()
 
 	return nil
@@ -101,6 +114,15 @@ func RunAutoCrawler(cmd *cobra.Command, args []string) error {
 		WriteTimeout: config.Config.Indexer.Server.WriteTimeout,
 	}
 
+	// The server has no handler of its own, the crawlers report their lag on the default mux next to pprof
+	http.Handle("/metrics", promhttp.Handler())
+	http.Handle("/healthz", health.LivenessHandler())
+	http.Handle("/readyz", health.ReadinessHandler(
+		health.Postgres(func() *gorm.DB { return database.DB }),
+		health.Redis(cache.GetRedisClient),
+		health.JSONRPC("gateway", config.Config.Indexer.Gateway.Endpoint),
+	))
+
 	// zksync
 	go zksync.Start()
 
@@ -122,9 +144,12 @@ func RunAutoCrawler(cmd *cobra.Command, args []string) error {
 	//arweave crawler
 	ar := arweave.NewCrawler(arweave.MirrorUploader, arweave.DefaultCrawlConfig)
 
-	if err := ar.Start(); err != nil {
-		logger.Errorf("arweave crawler start error: %v", err)
-	}
+	// The crawler runs until it fails, the server must not wait for it
+	go func() {
+		if err := ar.Start(); err != nil {
+			logger.Errorf("arweave crawler start error: %v", err)
+		}
+	}()
 
 	srv.Start()
 
@@ -146,6 +171,7 @@ func main() {
 		Use:  "autocrawler",
 		RunE: RunAutoCrawler,
 	})
+	rootCmd.AddCommand(newDeadLettersCommand())
 
 	if err := rootCmd.Execute(); err != nil {
 		panic(err)
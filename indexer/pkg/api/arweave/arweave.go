@@ -6,6 +6,7 @@ import (
 
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/httpx"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/metrics"
 	jsoniter "github.com/json-iterator/go"
 )
 
@@ -20,6 +21,9 @@ var (
 // GetLatestBlockHeight gets the latest block height for arweave
 func GetLatestBlockHeight() (int64, error) {
 	response, err := httpx.Get(arweaveEndpoint, nil)
+
+	metrics.CountExternalRequest(metrics.APIArweave, err)
+
 	if err != nil {
 		return 0, err
 	}
@@ -52,6 +56,9 @@ func GetContentByTxHash(hash string) ([]byte, error) {
 	}
 
 	resp, err := httpx.Get(arweaveEndpoint+"/"+hash, headers)
+
+	metrics.CountExternalRequest(metrics.APIArweave, err)
+
 	if err != nil {
 		return nil, err
 	}
@@ -101,6 +108,8 @@ func GetTransactions(from, to int64, owner ArAccount, cursor string) ([]byte, er
 
 	resp, err := httpx.Post(arweaveGraphqlEndpoint, headers, json)
 
+	metrics.CountExternalRequest(metrics.APIArweave, err)
+
 	return resp.Body, err
 }
 
@@ -13,6 +13,7 @@ import (
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/metrics"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
 )
 
@@ -72,6 +73,8 @@ func (ar *crawler) run() error {
 			return err
 		}
 
+		metrics.SetCrawlerHead(crawlerMetadataId, latestConfirmedBlockHeight)
+
 		if latestConfirmedBlockHeight <= endBlockHeight {
 			logger.Infof("catch up with the latest block height (%d)... will sleep for %v", latestConfirmedBlockHeight, ar.cfg.sleepInterval)
 			time.Sleep(ar.cfg.sleepInterval)
@@ -0,0 +1,78 @@
+package crossbell
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/crawler"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
+)
+
+type crossbellCrawler struct {
+	crawler.DefaultCrawler
+}
+
+func init() {
+	crawler.Register(crawler.Registration{
+		Name:         "crossbell",
+		Networks:     []constants.NetworkID{constants.NetworkIDCrossbell},
+		Capabilities: []crawler.Capability{crawler.CapabilityLinks},
+		New:          NewCrossbellCrawler,
+	})
+}
+
+func NewCrossbellCrawler() crawler.Crawler {
+	return &crossbellCrawler{
+		crawler.DefaultCrawler{
+			Links: []model.Link{},
+		},
+	}
+}
+
+// Work crawls the follows of the characters owned by the address,
+// a character following another one links their owners.
+func (cc *crossbellCrawler) Work(ctx context.Context, param crawler.WorkParam) error {
+	if param.NetworkID != constants.NetworkIDCrossbell {
+		return fmt.Errorf("network is not crossbell")
+	}
+
+	characters, err := GetCharacters(ctx, param.Identity)
+	if err != nil {
+		return fmt.Errorf("crossbell [%s] get characters error: %w", param.Identity, err)
+	}
+
+	origin := rss3uri.NewAccountInstance(param.Identity, constants.PlatformSymbolEthereum).UriString()
+
+	for _, character := range characters {
+		links, err := GetLinks(ctx, character.CharacterID, LinkTypeFollow)
+		if err != nil {
+			return fmt.Errorf("crossbell [%d] get links error: %w", character.CharacterID, err)
+		}
+
+		for _, link := range links {
+			// Characters may also link to addresses or notes, only the characters have an owner to follow
+			if link.ToCharacter == nil || link.ToCharacter.Owner == "" {
+				continue
+			}
+
+			cc.Links = append(cc.Links, model.Link{
+				Type:   constants.LinkTypeFollow.Int(),
+				Origin: origin,
+				Target: rss3uri.NewAccountInstance(link.ToCharacter.Owner, constants.PlatformSymbolEthereum).UriString(),
+				Source: constants.LinkSourceIDCrossbell.Int(),
+				Metadata: database.MustWrapJSON(map[string]interface{}{
+					"from_character_id": link.FromCharacterID,
+					"to_character_id":   link.ToCharacterID,
+					"proof":             link.TransactionHash,
+				}),
+				DateCreated: link.CreatedAt,
+				DateUpdated: link.UpdatedAt,
+			})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package crossbell
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/crawler"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/httpx"
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	endpoint = "https://indexer.crossbell.io"
+
+	LinkTypeFollow = "follow"
+
+	pageLimit = 100
+)
+
+var jsoni = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// GetCharacters returns the characters owned by the address.
+func GetCharacters(ctx context.Context, address string) ([]Character, error) {
+	if len(address) == 0 {
+		return nil, fmt.Errorf("address is empty")
+	}
+
+	return getAll[Character](ctx, fmt.Sprintf("%s/v1/addresses/%s/characters", endpoint, address), url.Values{})
+}
+
+// GetLinks returns the links of the type starting from the character.
+func GetLinks(ctx context.Context, characterID int64, linkType string) ([]Link, error) {
+	query := url.Values{}
+	query.Set("linkType", linkType)
+
+	return getAll[Link](ctx, fmt.Sprintf("%s/v1/characters/%d/links", endpoint, characterID), query)
+}
+
+// getAll follows the cursors until the last page
+func getAll[T any](ctx context.Context, path string, query url.Values) ([]T, error) {
+	result := make([]T, 0)
+
+	query.Set("limit", fmt.Sprint(pageLimit))
+
+	for {
+		response, err := httpx.NoCacheGetWithContext(ctx, path+"?"+query.Encode(), nil)
+		if err != nil {
+			return result, err
+		}
+
+		page := ListResponse[T]{}
+		if err := jsoni.Unmarshal(response.Body, &page); err != nil {
+			return result, fmt.Errorf("%w: %v", crawler.ErrParse, err)
+		}
+
+		result = append(result, page.List...)
+
+		if page.Cursor == "" || len(page.List) == 0 {
+			return result, nil
+		}
+
+		query.Set("cursor", page.Cursor)
+	}
+}
@@ -0,0 +1,31 @@
+package crossbell_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/api/crossbell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLinks(t *testing.T) {
+	t.Parallel()
+
+	// The first character of Crossbell
+	links, err := crossbell.GetLinks(context.Background(), 1, crossbell.LinkTypeFollow)
+
+	assert.Nil(t, err)
+
+	for _, link := range links {
+		assert.Equal(t, int64(1), link.FromCharacterID)
+		assert.Equal(t, crossbell.LinkTypeFollow, link.LinkType)
+	}
+}
+
+func TestGetCharactersEmptyAddress(t *testing.T) {
+	t.Parallel()
+
+	_, err := crossbell.GetCharacters(context.Background(), "")
+
+	assert.Error(t, err)
+}
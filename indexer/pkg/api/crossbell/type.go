@@ -0,0 +1,26 @@
+package crossbell
+
+import "time"
+
+type Character struct {
+	CharacterID int64  `json:"characterId"` // nolint:tagliatelle // named by the Crossbell indexer
+	Handle      string `json:"handle"`
+	Owner       string `json:"owner"`
+}
+
+type Link struct {
+	LinkType        string     `json:"linkType"`        // nolint:tagliatelle // named by the Crossbell indexer
+	FromCharacterID int64      `json:"fromCharacterId"` // nolint:tagliatelle // named by the Crossbell indexer
+	ToCharacterID   int64      `json:"toCharacterId"`   // nolint:tagliatelle // named by the Crossbell indexer
+	ToCharacter     *Character `json:"toCharacter"`     // nolint:tagliatelle // named by the Crossbell indexer
+	TransactionHash string     `json:"transactionHash"` // nolint:tagliatelle // named by the Crossbell indexer
+	CreatedAt       time.Time  `json:"createdAt"`       // nolint:tagliatelle // named by the Crossbell indexer
+	UpdatedAt       time.Time  `json:"updatedAt"`       // nolint:tagliatelle // named by the Crossbell indexer
+}
+
+// ListResponse is a page of the Crossbell indexer, the cursor is null on the last page
+type ListResponse[T any] struct {
+	List   []T    `json:"list"`
+	Count  int64  `json:"count"`
+	Cursor string `json:"cursor"`
+}
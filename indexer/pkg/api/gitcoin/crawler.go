@@ -15,6 +15,7 @@ import (
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/metrics"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
 	"go.opentelemetry.io/otel"
 )
@@ -161,6 +162,8 @@ func (property *zksyncCrawlerProperty) run() error {
 		return err
 	}
 
+	metrics.SetCrawlerHead(property.metadataIdentity, latestConfirmedBlockHeight)
+
 	// scan the latest block content periodically
 	endBlockHeight := config.FromHeight + config.Step - 1
 	if endBlockHeight <= 0 {
@@ -244,6 +247,8 @@ func (property *xscanRunCrawlerProperty) run() error {
 		return err
 	}
 
+	metrics.SetCrawlerHead(property.metadataIdentity, latestConfirmedBlockHeight)
+
 	endBlockHeight := config.FromHeight + config.Step - 1
 
 	if latestConfirmedBlockHeight < endBlockHeight {
@@ -20,6 +20,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/multierr"
 )
 
 var nativeMap = map[constants.NetworkSymbol]string{
@@ -41,6 +42,21 @@ type moralisCrawler struct {
 	crawler.DefaultCrawler
 }
 
+func init() {
+	crawler.Register(crawler.Registration{
+		Name: "moralis",
+		Networks: []constants.NetworkID{
+			constants.NetworkIDEthereum,
+			constants.NetworkIDBNBChain,
+			constants.NetworkIDAvalanche,
+			constants.NetworkIDPolygon,
+		},
+		Platforms:    []constants.PlatformID{constants.PlatformIDEthereum},
+		Capabilities: []crawler.Capability{crawler.CapabilityNotes, crawler.CapabilityAssets},
+		New:          NewMoralisCrawler,
+	})
+}
+
 func NewMoralisCrawler() crawler.Crawler {
 	return &moralisCrawler{
 		crawler.DefaultCrawler{
@@ -91,6 +107,8 @@ func (c *moralisCrawler) setNFTTransfers(
 		wg           sync.WaitGroup
 		nftTransfers = []NFTTransferItem{}
 		assets       = []NFTItem{}
+
+		nftTransfersErr, assetsErr error
 	)
 
 	// nftTransfers for notes
@@ -102,11 +120,9 @@ func (c *moralisCrawler) setNFTTransfers(
 			recover()
 		}()
 
-		var err error
-
-		nftTransfers, err = GetNFTTransfers(ctx, param.Identity, chainType, param.BlockHeight, param.Timestamp.String(), getApiKey())
-		if err != nil {
-			logger.Errorf("moralis.GetNFTTransfers: get nft transfers: %v", err)
+		nftTransfers, nftTransfersErr = GetNFTTransfers(ctx, param.Identity, chainType, param.BlockHeight, param.Timestamp.String(), getApiKey())
+		if nftTransfersErr != nil {
+			logger.Errorf("moralis.GetNFTTransfers: get nft transfers: %v", nftTransfersErr)
 
 			return
 		}
@@ -119,11 +135,9 @@ func (c *moralisCrawler) setNFTTransfers(
 			recover()
 		}()
 
-		var err error
-
-		assets, err = GetNFTs(ctx, param.Identity, chainType, param.Timestamp.String(), getApiKey())
-		if err != nil {
-			logger.Errorf("moralis.GetNFTs: get nft: %v", err)
+		assets, assetsErr = GetNFTs(ctx, param.Identity, chainType, param.Timestamp.String(), getApiKey())
+		if assetsErr != nil {
+			logger.Errorf("moralis.GetNFTs: get nft: %v", assetsErr)
 
 			return
 		}
@@ -268,7 +282,8 @@ func (c *moralisCrawler) setNFTTransfers(
 		c.Assets = append(c.Assets, asset)
 	}
 
-	return nil
+	// The notes and assets of the succeeded request are kept all the same
+	return multierr.Combine(nftTransfersErr, assetsErr)
 }
 
 // ERC20 used
@@ -515,10 +530,10 @@ func (c *moralisCrawler) setNative(
 }
 
 //nolint:funlen // disable line length check
-func (c *moralisCrawler) Work(param crawler.WorkParam) error {
+func (c *moralisCrawler) Work(ctx context.Context, param crawler.WorkParam) error {
 	tracer := otel.Tracer(TracerNameCrawlerMoralis)
 
-	ctx, workSpan := tracer.Start(context.Background(), "work")
+	ctx, workSpan := tracer.Start(ctx, "work")
 
 	workSpan.SetAttributes(
 		attribute.String("identity", param.Identity),
@@ -544,6 +559,8 @@ func (c *moralisCrawler) Work(param crawler.WorkParam) error {
 		owner         = rss3uri.NewAccountInstance(param.OwnerID, param.OwnerPlatformID.Symbol()).UriString()
 		author        = rss3uri.NewAccountInstance(param.Identity, constants.PlatformSymbolEthereum).UriString()
 		wg            sync.WaitGroup
+
+		nftTransfersErr, erc20Err, nativeErr error
 	)
 
 	wg.Add(3)
@@ -554,9 +571,9 @@ func (c *moralisCrawler) Work(param crawler.WorkParam) error {
 			recover()
 		}()
 
-		err := c.setNFTTransfers(ctx, param, owner, author, networkSymbol, chainType)
-		if err != nil {
-			logger.Errorf("moralis.setNFTTransfers: fail to set nft transfers in db: %v", err)
+		nftTransfersErr = c.setNFTTransfers(ctx, param, owner, author, networkSymbol, chainType)
+		if nftTransfersErr != nil {
+			logger.Errorf("moralis.setNFTTransfers: fail to set nft transfers in db: %v", nftTransfersErr)
 
 			return
 		}
@@ -568,9 +585,9 @@ func (c *moralisCrawler) Work(param crawler.WorkParam) error {
 			recover()
 		}()
 
-		err := c.setERC20(ctx, param, owner, author, networkSymbol, chainType)
-		if err != nil {
-			logger.Errorf("moralis.setERC20: fail to set erc20 in db: %v", err)
+		erc20Err = c.setERC20(ctx, param, owner, author, networkSymbol, chainType)
+		if erc20Err != nil {
+			logger.Errorf("moralis.setERC20: fail to set erc20 in db: %v", erc20Err)
 
 			return
 		}
@@ -582,9 +599,9 @@ func (c *moralisCrawler) Work(param crawler.WorkParam) error {
 			recover()
 		}()
 
-		err := c.setNative(ctx, param, owner, author, networkSymbol, chainType)
-		if err != nil {
-			logger.Errorf("moralis.setNative: fail to set eth in db: %v", err)
+		nativeErr = c.setNative(ctx, param, owner, author, networkSymbol, chainType)
+		if nativeErr != nil {
+			logger.Errorf("moralis.setNative: fail to set eth in db: %v", nativeErr)
 
 			return
 		}
@@ -592,6 +609,11 @@ func (c *moralisCrawler) Work(param crawler.WorkParam) error {
 
 	wg.Wait()
 
+	// Keep what has been crawled before the deadline, the rest is picked up next time
+	if err := ctx.Err(); err != nil {
+		logger.Warnf("moralis.Work: %s on %s stopped early: %v", param.Identity, networkSymbol, err)
+	}
+
 	// Duplication is not expected. But just in case, we double check it
 	// and leave some debug info for future analysis.
 
@@ -628,5 +650,5 @@ func (c *moralisCrawler) Work(param crawler.WorkParam) error {
 		}
 	}
 
-	return nil
+	return multierr.Combine(nftTransfersErr, erc20Err, nativeErr)
 }
@@ -0,0 +1,234 @@
+package moralis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/crawler"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/cache"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/config"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/httpx"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/metrics"
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	governorKeyPrefix = "moralis.ratelimit"
+
+	// DefaultRateLimit and DefaultInteractiveReserve are used if `indexer.moralis` is not configured
+	DefaultRateLimit          = 1500
+	DefaultInteractiveReserve = 0.2
+
+	// DefaultWeight is taken for every request. The weights of the endpoints differ,
+	// 8 is in between, see https://docs.moralis.io/misc/rate-limit#request-weights
+	DefaultWeight = 8
+
+	// governorMaxSleep lets a waiting request try again early, a response may have refilled the bucket
+	governorMaxSleep = time.Second
+	// governorInteractiveHold is how long a waiting interactive request holds back the background ones between its tries
+	governorInteractiveHold = 2 * governorMaxSleep
+	// governorExpiration forgets the limit reported by Moralis once nothing has been requested for a while
+	governorExpiration = time.Hour
+)
+
+// takeTokens refills the bucket for the elapsed time, then takes the weight of a request if the tokens
+// above the floor of the priority allow it. It returns 0 if the weight was taken, otherwise the
+// milliseconds to wait. A waiting interactive request holds back the background ones until it is served,
+// or for a while if it has given up.
+var takeTokens = redis.NewScript(`
+local now = tonumber(ARGV[2])
+local reserve = tonumber(ARGV[4])
+local interactive = ARGV[5] == "1"
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "timestamp", "limit", "interactive_until")
+local limit = tonumber(bucket[3]) or tonumber(ARGV[1])
+local rate = limit / 60000
+local tokens = tonumber(bucket[1]) or limit
+local timestamp = tonumber(bucket[2]) or now
+local interactiveUntil = tonumber(bucket[4]) or 0
+local weight = math.min(limit, tonumber(ARGV[3]))
+
+tokens = math.min(limit, tokens + math.max(0, now - timestamp) * rate)
+
+local floor = limit * reserve
+local wait = 0
+
+if not interactive and interactiveUntil > now then
+	wait = interactiveUntil - now
+elseif tokens - weight >= floor then
+	tokens = tokens - weight
+
+	if interactive then
+		interactiveUntil = 0
+	end
+else
+	wait = math.ceil((floor + weight - tokens) / rate)
+
+	if interactive then
+		interactiveUntil = math.max(interactiveUntil, now + math.min(wait, tonumber(ARGV[7])))
+	end
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "timestamp", now, "interactive_until", interactiveUntil)
+redis.call("PEXPIRE", KEYS[1], ARGV[6])
+
+return wait
+`)
+
+// Governor is a token bucket in Redis which paces the requests of all the indexer processes sharing
+// an API key. It holds the weight of the requests Moralis allows a minute, and follows the limit and
+// the usage Moralis reports in the headers of its responses.
+type Governor struct {
+	Key       string
+	RateLimit int
+	// InteractiveReserve is the share of the bucket only interactive requests may take
+	InteractiveReserve float64
+	Weight             int
+}
+
+var (
+	governorsMutex sync.Mutex
+	governors      = map[string]*Governor{}
+)
+
+// NewGovernor returns the governor of the API key, the key itself is not kept in Redis.
+func NewGovernor(apiKey string) *Governor {
+	moralisConfig := config.Config.Indexer.Moralis
+	sum := sha256.Sum256([]byte(apiKey))
+
+	governor := &Governor{
+		Key:                cache.ConstructKey(governorKeyPrefix, hex.EncodeToString(sum[:8])),
+		RateLimit:          moralisConfig.RateLimit,
+		InteractiveReserve: moralisConfig.InteractiveReserve,
+		Weight:             DefaultWeight,
+	}
+
+	if governor.RateLimit <= 0 {
+		governor.RateLimit = DefaultRateLimit
+	}
+
+	if governor.InteractiveReserve <= 0 || governor.InteractiveReserve >= 1 {
+		governor.InteractiveReserve = DefaultInteractiveReserve
+	}
+
+	return governor
+}
+
+func governorOf(apiKey string) *Governor {
+	governorsMutex.Lock()
+	defer governorsMutex.Unlock()
+
+	governor, exists := governors[apiKey]
+	if !exists {
+		governor = NewGovernor(apiKey)
+		governors[apiKey] = governor
+	}
+
+	return governor
+}
+
+// Wait blocks until a request of the priority of ctx may be sent, or ctx is done.
+// Requests are let through if Redis fails, the crawls must not stop with it.
+func (g *Governor) Wait(ctx context.Context) error {
+	priority := crawler.PriorityOf(ctx)
+	start := time.Now()
+
+	defer metrics.ObserveMoralisWait(string(priority), start)
+
+	for {
+		wait, err := g.take(ctx, priority)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			logger.Errorf("moralis governor %s error: %v", g.Key, err)
+
+			return nil
+		}
+
+		if wait == 0 {
+			return nil
+		}
+
+		if wait > governorMaxSleep {
+			wait = governorMaxSleep
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (g *Governor) take(ctx context.Context, priority crawler.Priority) (time.Duration, error) {
+	reserve, interactive := g.InteractiveReserve, 0
+	if priority == crawler.PriorityInteractive {
+		reserve, interactive = 0, 1
+	}
+
+	wait, err := takeTokens.Run(
+		ctx,
+		cache.GetRedisClient(),
+		[]string{g.Key},
+		g.RateLimit, time.Now().UnixMilli(), g.Weight, reserve, interactive,
+		governorExpiration.Milliseconds(), governorInteractiveHold.Milliseconds(),
+	).Int64()
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(wait) * time.Millisecond, nil
+}
+
+// Observe sets the bucket to what is left of the limit Moralis reports in the response,
+// and empties it if the request was rate limited. Responses without the headers are ignored.
+func (g *Governor) Observe(ctx context.Context, response httpx.Response, err error) {
+	attributes := MoralisAttributes{}
+	SetMoralisAttributes(&attributes, response)
+
+	var statusErr *httpx.StatusError
+
+	fields := map[string]interface{}{"timestamp": time.Now().UnixMilli()}
+
+	switch {
+	case errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusTooManyRequests:
+		fields["tokens"] = 0
+	case attributes.MinRateLimit > 0:
+		tokens := attributes.MinRateLimit - attributes.MinRateLimitUsed
+		if tokens < 0 {
+			tokens = 0
+		}
+
+		fields["tokens"] = tokens
+	default:
+		return
+	}
+
+	if attributes.MinRateLimit > 0 {
+		fields["limit"] = attributes.MinRateLimit
+	}
+
+	client := cache.GetRedisClient()
+
+	if _, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, g.Key, fields)
+		pipe.PExpire(ctx, g.Key, governorExpiration)
+
+		return nil
+	}); err != nil {
+		logger.Errorf("moralis governor %s observe error: %v", g.Key, err)
+	}
+}
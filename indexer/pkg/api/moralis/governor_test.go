@@ -0,0 +1,62 @@
+package moralis_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/api/moralis"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/crawler"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/httpx"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGovernor(t *testing.T) {
+	ctx := context.Background()
+	interactive := crawler.WithPriority(ctx, crawler.PriorityInteractive)
+
+	// A token is refilled every second, too slow to matter within the waits
+	governor := &moralis.Governor{
+		Key:                uuid.NewString(),
+		RateLimit:          60,
+		InteractiveReserve: 0.5,
+		Weight:             10,
+	}
+
+	wait := func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+		defer cancel()
+
+		return governor.Wait(ctx)
+	}
+
+	// The background requests stop at the reserve
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, wait(ctx))
+	}
+
+	assert.ErrorIs(t, wait(ctx), context.DeadlineExceeded)
+
+	// The interactive requests take the reserve
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, wait(interactive))
+	}
+
+	assert.ErrorIs(t, wait(interactive), context.DeadlineExceeded)
+
+	// The bucket follows the limit and the usage reported by Moralis
+	governor.Observe(ctx, httpx.Response{Header: http.Header{
+		"X-Rate-Limit-Limit": []string{"600"},
+		"X-Rate-Limit-Used":  []string{"100"},
+	}}, nil)
+
+	assert.Nil(t, wait(interactive))
+	assert.Nil(t, wait(ctx))
+
+	// A rate limited request empties it
+	governor.Observe(ctx, httpx.Response{}, &httpx.StatusError{StatusCode: http.StatusTooManyRequests})
+
+	assert.ErrorIs(t, wait(interactive), context.DeadlineExceeded)
+}
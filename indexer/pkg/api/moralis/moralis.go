@@ -8,11 +8,13 @@ import (
 	"time"
 
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/api/nft_utils"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/crawler"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/datatype"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/httpx"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/metrics"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	jsoniter "github.com/json-iterator/go"
@@ -48,16 +50,33 @@ func requestMoralisApi(ctx context.Context, url string, apiKey string, isCache b
 		"X-API-Key": apiKey,
 	}
 
+	if isCache {
+		// A cached response costs nothing of the rate limit
+		if response, ok := httpx.GetCache(url); ok {
+			return response, nil
+		}
+	}
+
+	governor := governorOf(apiKey)
+
+	if err := governor.Wait(ctx); err != nil {
+		return httpx.Response{}, fmt.Errorf("wait for the rate limit: %w", err)
+	}
+
 	var err error
 
 	var response httpx.Response
 
 	if isCache {
-		response, err = httpx.Get(url, headers)
+		response, err = httpx.GetWithContext(ctx, url, headers)
 	} else {
-		response, err = httpx.NoCacheGet(url, headers)
+		response, err = httpx.NoCacheGetWithContext(ctx, url, headers)
 	}
 
+	governor.Observe(ctx, response, err)
+
+	metrics.CountExternalRequest(metrics.APIMoralis, err)
+
 	if err != nil {
 		logger.Errorf("http get error with url '%s': [%v]. response: %s",
 			url, err, string(response.Body))
@@ -68,6 +87,15 @@ func requestMoralisApi(ctx context.Context, url string, apiKey string, isCache b
 	return response, nil
 }
 
+// unmarshal tells the responses Moralis fails to encode from the failed requests
+func unmarshal(data []byte, v interface{}) error {
+	if err := jsoni.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %v", crawler.ErrParse, err)
+	}
+
+	return nil
+}
+
 /*
  * About nft handler
  */
@@ -137,7 +165,7 @@ func GetNFTs(
 				go func() {
 					url := nft_utils.FormatUrl(item.TokenURI)
 
-					if metadataRes, err := httpx.Get(url, nil); err != nil {
+					if metadataRes, err := httpx.GetWithContext(ctx, url, nil); err != nil {
 						getNFTsSnap.RecordError(err)
 						getNFTsSnap.SetStatus(codes.Error, err.Error())
 
@@ -196,7 +224,7 @@ func getNFTsOnce(
 	res := new(NFTResult)
 	SetMoralisAttributes(&res.MoralisAttributes, response)
 
-	err = jsoni.Unmarshal(response.Body, &res)
+	err = unmarshal(response.Body, &res)
 	if err != nil {
 		getNFTListSnap.RecordError(err)
 		getNFTListSnap.SetStatus(codes.Error, err.Error())
@@ -293,7 +321,7 @@ func getNFTTransfersOnce(
 		attribute.Int("response_size", len(response.Body)),
 	)
 
-	err = jsoni.Unmarshal(response.Body, &res)
+	err = unmarshal(response.Body, &res)
 	if err != nil {
 		return NFTTransferResult{}, err
 	}
@@ -389,7 +417,7 @@ func getLogsFromUrl(
 	res := GetLogsResult{}
 	SetMoralisAttributes(&res.MoralisAttributes, response)
 
-	err = jsoni.Unmarshal(response.Body, &res)
+	err = unmarshal(response.Body, &res)
 	if err != nil {
 		logger.Errorf("unmarshal error: [%v]", err)
 
@@ -463,7 +491,7 @@ func GetNFTByContract(ctx context.Context, userAddress string, contactAddress st
 	res := new(NFTResult)
 	SetMoralisAttributes(&res.MoralisAttributes, response)
 
-	err = jsoni.Unmarshal(response.Body, &res)
+	err = unmarshal(response.Body, &res)
 	if err != nil {
 		return NFTResult{}, err
 	}
@@ -522,7 +550,7 @@ func GetMetadataByToken(ctx context.Context, tokenAddress string, tokenId string
 	res := new(NFTItem)
 	SetMoralisAttributes(&res.MoralisAttributes, response)
 
-	err = jsoni.Unmarshal(response.Body, &res)
+	err = unmarshal(response.Body, &res)
 	if err != nil {
 		return NFTItem{}, nil
 	}
@@ -610,7 +638,7 @@ func getErc20Once(
 	res := new(ERC20Transfer)
 	SetMoralisAttributes(&res.MoralisAttributes, response)
 
-	if err = jsoni.Unmarshal(response.Body, &res); err != nil {
+	if err = unmarshal(response.Body, &res); err != nil {
 		return nil, err
 	}
 
@@ -700,7 +728,7 @@ func getErc20TokenMetaDataFromUrl(ctx context.Context, chainType ChainType, addr
 	attributes := new(MoralisAttributes)
 	SetMoralisAttributes(attributes, response)
 
-	err = jsoni.Unmarshal(response.Body, &resp)
+	err = unmarshal(response.Body, &resp)
 	if err != nil {
 		return err
 	}
@@ -963,7 +991,7 @@ func getETHOnce(
 	res := new(ETHTransfer)
 	SetMoralisAttributes(&res.MoralisAttributes, response)
 
-	if err = jsoni.Unmarshal(response.Body, &res); err != nil {
+	if err = unmarshal(response.Body, &res); err != nil {
 		return nil, err
 	}
 
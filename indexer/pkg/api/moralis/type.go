@@ -8,6 +8,7 @@ import (
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/datatype"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/httpx"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/metrics"
 )
 
 type ChainType string
@@ -70,11 +71,13 @@ func SetMoralisAttributes(attributes *MoralisAttributes, response httpx.Response
 	MinRateLimitStr := response.Header.Get("x-rate-limit-limit")
 	if MinRateLimitStr != "" {
 		attributes.MinRateLimit, _ = strconv.Atoi(MinRateLimitStr)
+		metrics.MoralisRateLimit.WithLabelValues("limit").Set(float64(attributes.MinRateLimit))
 	}
 
 	MinRateLimitUsedStr := response.Header.Get("x-rate-limit-used")
 	if MinRateLimitUsedStr != "" {
 		attributes.MinRateLimitUsed, _ = strconv.Atoi(MinRateLimitUsedStr)
+		metrics.MoralisRateLimit.WithLabelValues("used").Set(float64(attributes.MinRateLimitUsed))
 	}
 }
 
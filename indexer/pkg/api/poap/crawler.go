@@ -21,6 +21,15 @@ type poapCrawler struct {
 	crawler.DefaultCrawler
 }
 
+func init() {
+	crawler.Register(crawler.Registration{
+		Name:         "poap",
+		Networks:     []constants.NetworkID{constants.NetworkIDGnosisMainnet},
+		Capabilities: []crawler.Capability{crawler.CapabilityNotes, crawler.CapabilityAssets},
+		New:          NewPoapCrawler,
+	})
+}
+
 func NewPoapCrawler() crawler.Crawler {
 	return &poapCrawler{
 		crawler.DefaultCrawler{
@@ -31,12 +40,12 @@ func NewPoapCrawler() crawler.Crawler {
 }
 
 // nolint:funlen // TODO
-func (pc *poapCrawler) Work(param crawler.WorkParam) error {
+func (pc *poapCrawler) Work(ctx context.Context, param crawler.WorkParam) error {
 	if param.NetworkID != constants.NetworkIDGnosisMainnet {
 		return fmt.Errorf("network is not gnosis")
 	}
 
-	poapResps, err := GetActions(param.Identity)
+	poapResps, err := GetActions(ctx, param.Identity)
 	if err != nil {
 		return fmt.Errorf("poap [%s] get actions error:", err)
 	}
@@ -129,7 +138,7 @@ func (pc *poapCrawler) Work(param crawler.WorkParam) error {
 			},
 		})
 
-		if err := nft_utils.CompleteMimeTypesForItems(context.Background(), pc.Notes, pc.Assets, pc.Profiles); err != nil {
+		if err := nft_utils.CompleteMimeTypesForItems(ctx, pc.Notes, pc.Assets, pc.Profiles); err != nil {
 			logger.Error("poap complete mime types error:", err)
 		}
 	}
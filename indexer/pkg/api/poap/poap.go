@@ -1,8 +1,10 @@
 package poap
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/crawler"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/httpx"
 	jsoniter "github.com/json-iterator/go"
 )
@@ -11,7 +13,7 @@ const endpoint = "https://api.poap.xyz"
 
 var jsoni = jsoniter.ConfigCompatibleWithStandardLibrary
 
-func GetActions(user string) ([]PoapResponse, error) {
+func GetActions(ctx context.Context, user string) ([]PoapResponse, error) {
 	if len(user) == 0 {
 		var err = fmt.Errorf("user address is empty")
 
@@ -19,7 +21,7 @@ func GetActions(user string) ([]PoapResponse, error) {
 	}
 
 	url := fmt.Sprintf("%s/actions/scan/%s", endpoint, user)
-	response, err := httpx.Get(url, nil)
+	response, err := httpx.GetWithContext(ctx, url, nil)
 
 	if err != nil {
 		return []PoapResponse{}, err
@@ -29,7 +31,7 @@ func GetActions(user string) ([]PoapResponse, error) {
 
 	err = jsoni.Unmarshal(response.Body, &res)
 	if err != nil {
-		return []PoapResponse{}, err
+		return []PoapResponse{}, fmt.Errorf("%w: %v", crawler.ErrParse, err)
 	}
 
 	return *res, nil
@@ -1,6 +1,7 @@
 package poap_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/api/poap"
@@ -8,7 +9,7 @@ import (
 )
 
 func TestGetActions(t *testing.T) {
-	result, err := poap.GetActions("0xBf6f8E4ae37680a60B13C2f02b6437e6737d5203")
+	result, err := poap.GetActions(context.Background(), "0xBf6f8E4ae37680a60B13C2f02b6437e6737d5203")
 
 	assert.Nil(t, err)
 
@@ -8,6 +8,7 @@ import (
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/config"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/httpx"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/metrics"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/valyala/fastjson"
 )
@@ -46,6 +47,9 @@ func GetLatestBlockHeight(networkId constants.NetworkID) (int64, error) {
 	}
 
 	response, err := httpx.Get(url, nil)
+
+	metrics.CountExternalRequest(metrics.APIXscan, err)
+
 	if err != nil {
 		return 0, err
 	}
@@ -12,6 +12,7 @@ import (
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/metrics"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/rss3uri"
 )
 
@@ -74,6 +75,8 @@ func (crawler *crawler) run() error {
 		return err
 	}
 
+	metrics.SetCrawlerHead(crawler.metadataIdentity, latestConfirmedBlockHeight)
+
 	// scan the latest block content periodically
 	endBlockHeight := config.FromHeight + config.Step - 1
 	if endBlockHeight <= 0 {
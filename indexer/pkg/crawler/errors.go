@@ -0,0 +1,10 @@
+package crawler
+
+import "errors"
+
+var (
+	// ErrParse wraps the errors of decoding the responses of the upstream APIs
+	ErrParse = errors.New("parse error")
+	// ErrUnsupportedNetwork is returned if no crawler serves the network
+	ErrUnsupportedNetwork = errors.New("unsupported network")
+)
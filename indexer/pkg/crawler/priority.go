@@ -0,0 +1,29 @@
+package crawler
+
+import "context"
+
+// Priority tells the rate limiters of the upstream APIs whether someone is waiting for a crawl.
+type Priority string
+
+const (
+	// PriorityInteractive crawls are waited for by the hub, they are served ahead of the others
+	PriorityInteractive Priority = "interactive"
+	// PriorityBackground is for the crawls nobody waits for, such as retries and the autoupdater
+	PriorityBackground Priority = "background"
+)
+
+type priorityKey struct{}
+
+// WithPriority returns a copy of ctx for the crawls of the priority.
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// PriorityOf returns the priority of the crawls of ctx, crawls are in the background unless told otherwise.
+func PriorityOf(ctx context.Context) Priority {
+	if priority, ok := ctx.Value(priorityKey{}).(Priority); ok && priority == PriorityInteractive {
+		return PriorityInteractive
+	}
+
+	return PriorityBackground
+}
@@ -0,0 +1,109 @@
+package crawler
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
+)
+
+type Capability string
+
+const (
+	CapabilityNotes    Capability = "notes"
+	CapabilityAssets   Capability = "assets"
+	CapabilityProfiles Capability = "profiles"
+	CapabilityLinks    Capability = "links"
+)
+
+// Registration describes a crawler, crawler packages register themselves in `init()`.
+type Registration struct {
+	Name         string
+	Networks     []constants.NetworkID
+	Platforms    []constants.PlatformID
+	Capabilities []Capability
+	New          func() Crawler
+}
+
+var (
+	registryMutex sync.RWMutex
+	registrations = map[string]Registration{}
+	networks      = map[constants.NetworkID]string{}
+	platforms     = map[constants.PlatformID]string{}
+)
+
+// Register panics if the name, a network or a platform is already registered,
+// as two crawlers serving the same network would be a programming error.
+func Register(registration Registration) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if _, exists := registrations[registration.Name]; exists {
+		panic(fmt.Sprintf("crawler %s is already registered", registration.Name))
+	}
+
+	for _, networkID := range registration.Networks {
+		if name, exists := networks[networkID]; exists {
+			panic(fmt.Sprintf("network %s of crawler %s is already served by %s", networkID.Symbol(), registration.Name, name))
+		}
+	}
+
+	for _, platformID := range registration.Platforms {
+		if name, exists := platforms[platformID]; exists {
+			panic(fmt.Sprintf("platform %s of crawler %s is already served by %s", platformID.Symbol(), registration.Name, name))
+		}
+	}
+
+	registrations[registration.Name] = registration
+
+	for _, networkID := range registration.Networks {
+		networks[networkID] = registration.Name
+	}
+
+	for _, platformID := range registration.Platforms {
+		platforms[platformID] = registration.Name
+	}
+}
+
+// ByNetwork returns a new crawler of the network, or nil if none serves it.
+func ByNetwork(networkID constants.NetworkID) Crawler {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	if name, exists := networks[networkID]; exists {
+		return registrations[name].New()
+	}
+
+	return nil
+}
+
+// ByPlatform returns a new crawler of the platform, or nil if none serves it.
+func ByPlatform(platformID constants.PlatformID) Crawler {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	if name, exists := platforms[platformID]; exists {
+		return registrations[name].New()
+	}
+
+	return nil
+}
+
+// Registrations returns the registered crawlers sorted by name.
+func Registrations() []Registration {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	result := make([]Registration, 0, len(registrations))
+
+	for _, registration := range registrations {
+		result = append(result, registration)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
@@ -0,0 +1,45 @@
+package crawler_test
+
+import (
+	"testing"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/crawler"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCrawler struct {
+	crawler.DefaultCrawler
+}
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	crawler.Register(crawler.Registration{
+		Name:         "test",
+		Networks:     []constants.NetworkID{constants.NetworkIDFantom},
+		Platforms:    []constants.PlatformID{constants.PlatformIDSolana},
+		Capabilities: []crawler.Capability{crawler.CapabilityNotes},
+		New: func() crawler.Crawler {
+			return &testCrawler{}
+		},
+	})
+
+	assert.IsType(t, &testCrawler{}, crawler.ByNetwork(constants.NetworkIDFantom))
+	assert.IsType(t, &testCrawler{}, crawler.ByPlatform(constants.PlatformIDSolana))
+	assert.Nil(t, crawler.ByNetwork(constants.NetworkIDArbitrum))
+
+	// Every call returns a new crawler as their results are kept in them
+	assert.NotSame(t, crawler.ByNetwork(constants.NetworkIDFantom), crawler.ByNetwork(constants.NetworkIDFantom))
+
+	assert.Panics(t, func() {
+		crawler.Register(crawler.Registration{
+			Name:     "duplicate",
+			Networks: []constants.NetworkID{constants.NetworkIDFantom},
+		})
+	})
+
+	registrations := crawler.Registrations()
+	assert.Len(t, registrations, 1)
+	assert.Equal(t, "test", registrations[0].Name)
+}
@@ -0,0 +1,27 @@
+package crawler
+
+import (
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/config"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
+)
+
+// DefaultTimeout is used if `indexer.crawler.timeout` is not configured.
+const DefaultTimeout = 30 * time.Second
+
+// Timeout returns how long a crawl of the network may take,
+// a per-network override takes precedence over the common timeout.
+func Timeout(networkID constants.NetworkID) time.Duration {
+	crawlerConfig := config.Config.Indexer.Crawler
+
+	if timeout, exists := crawlerConfig.NetworkTimeouts[string(networkID.Symbol())]; exists && timeout > 0 {
+		return timeout
+	}
+
+	if crawlerConfig.Timeout > 0 {
+		return crawlerConfig.Timeout
+	}
+
+	return DefaultTimeout
+}
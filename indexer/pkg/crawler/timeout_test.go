@@ -0,0 +1,33 @@
+package crawler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/crawler"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/config"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	crawlerConfig := config.Config.Indexer.Crawler
+
+	t.Cleanup(func() {
+		config.Config.Indexer.Crawler = crawlerConfig
+	})
+
+	config.Config.Indexer.Crawler = config.CrawlerStruct{}
+
+	assert.Equal(t, crawler.DefaultTimeout, crawler.Timeout(constants.NetworkIDEthereum))
+
+	config.Config.Indexer.Crawler = config.CrawlerStruct{
+		Timeout: time.Minute,
+		NetworkTimeouts: map[string]time.Duration{
+			string(constants.NetworkSymbolGnosisMainnet): 10 * time.Second,
+		},
+	}
+
+	assert.Equal(t, time.Minute, crawler.Timeout(constants.NetworkIDEthereum))
+	assert.Equal(t, 10*time.Second, crawler.Timeout(constants.NetworkIDGnosisMainnet))
+}
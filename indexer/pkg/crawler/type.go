@@ -1,6 +1,7 @@
 package crawler
 
 import (
+	"context"
 	"time"
 
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
@@ -9,7 +10,8 @@ import (
 )
 
 type Crawler interface {
-	Work(param WorkParam) error
+	// Work stops as soon as ctx is done, see Timeout for the deadline of a network
+	Work(ctx context.Context, param WorkParam) error
 	// GetResult return &{Assets, Notes, Items}
 	GetResult() *DefaultCrawler
 	// GetBio
@@ -22,13 +24,14 @@ type DefaultCrawler struct {
 	Assets   []model.Asset
 	Notes    []model.Note
 	Profiles []model.Profile
+	Links    []model.Link
 
 	Erc20Notes []model.Note // No way to fix bugs
 }
 
 // CrawlerResult inherits the function by default
 
-func (cr *DefaultCrawler) Work(param WorkParam) error {
+func (cr *DefaultCrawler) Work(ctx context.Context, param WorkParam) error {
 	return nil
 }
 
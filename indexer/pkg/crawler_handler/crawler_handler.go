@@ -1,11 +1,14 @@
 package crawler_handler
 
 import (
-	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/api/moralis"
-	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/api/poap"
+	"context"
+
+	// Crawlers register themselves to the registry
+	_ "github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/api/crossbell"
+	_ "github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/api/moralis"
+	_ "github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/api/poap"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/crawler"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/util"
-	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
 )
 
 type CrawlerHandlerResultInf interface {
@@ -17,38 +20,10 @@ type CrawlerHandlerResultBase struct {
 }
 
 type CrawlerHandlerInf interface {
-	Excute() CrawlerHandlerResultInf
+	Excute(ctx context.Context) CrawlerHandlerResultInf
 }
 
 type CrawlerHandlerBase struct {
 	WorkParam crawler.WorkParam
 	CrawlerHandlerInf
 }
-
-func MakeCrawlers[T constants.NetworkID | constants.PlatformID](network T) crawler.Crawler {
-	switch any(network).(type) {
-	case constants.NetworkID:
-		switch constants.NetworkID(network) {
-		case constants.NetworkIDEthereum,
-			constants.NetworkIDBNBChain,
-			constants.NetworkIDAvalanche,
-			constants.NetworkIDPolygon:
-			return moralis.NewMoralisCrawler()
-		case constants.NetworkIDGnosisMainnet:
-			return poap.NewPoapCrawler()
-		default:
-			return nil
-		}
-
-	case constants.PlatformID:
-		switch constants.PlatformID(network) {
-		case constants.PlatformIDEthereum:
-			return moralis.NewMoralisCrawler()
-		default:
-			return nil
-		}
-
-	default:
-		return nil
-	}
-}
@@ -1,6 +1,7 @@
 package crawler_handler
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/crawler"
@@ -35,18 +36,19 @@ func NewGetItemsResult() *GetItemsResult {
 	}
 }
 
-func (pt *GetItemsHandler) Excute() (*GetItemsResult, error) {
+// Excute crawls the network of the work param, giving up after crawler.Timeout of the network
+func (pt *GetItemsHandler) Excute(ctx context.Context) (*GetItemsResult, error) {
 	var c crawler.Crawler
 
 	var r *crawler.DefaultCrawler
 
 	result := NewGetItemsResult()
 
-	c = MakeCrawlers(pt.WorkParam.NetworkID)
+	c = crawler.ByNetwork(pt.WorkParam.NetworkID)
 	if c == nil {
 		result.Error = util.GetErrorBase(util.ErrorCodeNotSupportedNetwork)
 
-		return result, fmt.Errorf("unsupported network id[%d]", pt.WorkParam.NetworkID)
+		return result, fmt.Errorf("%w: id[%d]", crawler.ErrUnsupportedNetwork, pt.WorkParam.NetworkID)
 	}
 
 	metadata, dbQcmErr := database.QueryCrawlerMetadata(database.DB, pt.WorkParam.Identity, pt.WorkParam.PlatformID)
@@ -58,10 +60,14 @@ func (pt *GetItemsHandler) Excute() (*GetItemsResult, error) {
 		pt.WorkParam.Timestamp = metadata.UpdatedAt
 	}
 
-	if err := c.Work(pt.WorkParam); err != nil {
-		result.Error = util.GetErrorBase(util.ErrorCodeNotSupportedNetwork)
+	ctx, cancel := context.WithTimeout(ctx, crawler.Timeout(pt.WorkParam.NetworkID))
+	defer cancel()
 
-		return result, fmt.Errorf("crawler fails while working: %s", err)
+	// What has been crawled before a failure is saved all the same, a retry picks up the rest
+	workErr := c.Work(ctx, pt.WorkParam)
+	if workErr != nil {
+		result.Error = util.GetErrorBase(util.ErrorCodeGetDataError)
+		workErr = fmt.Errorf("crawler fails while working: %w", workErr)
 	}
 
 	r = c.GetResult()
@@ -84,6 +90,14 @@ func (pt *GetItemsHandler) Excute() (*GetItemsResult, error) {
 		}
 	}
 
+	if len(r.Links) > 0 {
+		if dbLinks, err := database.CreateLinks(db, r.Links, true); err != nil {
+			return result, err
+		} else {
+			r.Links = dbLinks
+		}
+	}
+
 	go func() {
 		if r.Assets != nil && len(r.Assets) > 0 {
 			if _, err := database.CreateAssets(db, r.Assets, true); err != nil {
@@ -94,5 +108,5 @@ func (pt *GetItemsHandler) Excute() (*GetItemsResult, error) {
 
 	result.Result = r
 
-	return result, nil
+	return result, workErr
 }
@@ -0,0 +1,83 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/crawler"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/cache"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
+)
+
+// DeadLetters returns the dead jobs from offset, the latest first, and the count of all of them.
+func DeadLetters(ctx context.Context, offset int64, limit int64) ([]*Job, int64, error) {
+	client := cache.GetRedisClient()
+
+	total, err := client.ZCard(ctx, DeadLetterKey).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids, err := client.ZRevRange(ctx, DeadLetterKey, offset, offset+limit-1).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	jobList := make([]*Job, 0, len(ids))
+
+	for _, id := range ids {
+		job, err := Get(ctx, id)
+		if err != nil {
+			// A dead job is kept until it is requeued, it may have been deleted by hand
+			if errors.Is(err, ErrNotFound) {
+				logger.Warnf("jobs: dead job %s not found", id)
+
+				continue
+			}
+
+			return nil, 0, err
+		}
+
+		jobList = append(jobList, job)
+	}
+
+	return jobList, total, nil
+}
+
+// Requeue gives a dead job its attempts again, only the networks which have failed are crawled.
+func Requeue(ctx context.Context, id string) (*Job, error) {
+	job, err := Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status != StatusDead {
+		return nil, fmt.Errorf("job %s is %s, only dead jobs can be requeued", job.ID, job.Status)
+	}
+
+	job.Status = StatusQueued
+	job.Attempts = 0
+	job.Class = ""
+	job.NextAttemptAt = nil
+	job.Priority = crawler.PriorityBackground
+
+	// The account is taken over from any job of it, the dead job has the failures to retry
+	if err := cache.SetRaw(ctx, job.Param.accountKey(), job.ID, retention()); err != nil {
+		return nil, err
+	}
+
+	if err := save(ctx, job); err != nil {
+		return nil, err
+	}
+
+	if err := cache.GetRedisClient().ZRem(ctx, DeadLetterKey, job.ID).Err(); err != nil {
+		return nil, err
+	}
+
+	if err := add(ctx, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
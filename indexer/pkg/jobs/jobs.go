@@ -0,0 +1,352 @@
+/*
+Package jobs queues the crawls of accounts in a Redis stream,
+so that callers can poll the results instead of waiting on the crawlers.
+*/
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/crawler"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/cache"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/config"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+const (
+	StreamKey = "index.item.jobs"
+	GroupName = "indexer"
+	// DelayedKey is a sorted set of the IDs of the jobs waiting for a retry, scored by when to retry them
+	DelayedKey = "index.item.jobs.delayed"
+	// DeadLetterKey is a sorted set of the IDs of the jobs out of attempts, scored by when they failed
+	DeadLetterKey = "index.item.jobs.dead"
+
+	jobKeyPrefix     = "index.item.job"
+	accountKeyPrefix = "index.item.job.account"
+	// DefaultWorkers, DefaultDedupWindow and DefaultRetention are used if `indexer.jobs` is not configured
+	DefaultWorkers     = 4
+	DefaultDedupWindow = 10 * time.Second
+	DefaultRetention   = time.Hour
+)
+
+var ErrNotFound = errors.New("job not found")
+
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	// StatusFailed is only given to jobs of unsupported networks, the others are retried
+	StatusFailed   Status = "failed"
+	StatusRetrying Status = "retrying"
+	// StatusDead jobs are kept in the dead-letter set until they are requeued
+	StatusDead Status = "dead"
+)
+
+// Param is what the crawlers need to know about an account, see crawler.WorkParam.
+// An unknown network crawls every network of the Ethereum platform.
+type Param struct {
+	Identity        string                    `json:"proof"`
+	PlatformID      constants.PlatformID      `json:"platform_id"`
+	NetworkID       constants.NetworkID       `json:"network_id"`
+	Limit           int                       `json:"limit"`
+	Timestamp       time.Time                 `json:"timestamp"`
+	OwnerID         string                    `json:"owner_id"`
+	OwnerPlatformID constants.PlatformID      `json:"owner_platform_id"`
+	ProfileSourceID constants.ProfileSourceID `json:"profile_source_id"`
+}
+
+func FromWorkParam(param crawler.WorkParam) Param {
+	return Param{
+		Identity:        param.Identity,
+		PlatformID:      param.PlatformID,
+		NetworkID:       param.NetworkID,
+		Limit:           param.Limit,
+		Timestamp:       param.Timestamp,
+		OwnerID:         param.OwnerID,
+		OwnerPlatformID: param.OwnerPlatformID,
+		ProfileSourceID: param.ProfileSourceID,
+	}
+}
+
+func (p Param) WorkParam(networkID constants.NetworkID) crawler.WorkParam {
+	return crawler.WorkParam{
+		Identity:        p.Identity,
+		NetworkID:       networkID,
+		PlatformID:      p.PlatformID,
+		Limit:           p.Limit,
+		Timestamp:       p.Timestamp,
+		OwnerID:         p.OwnerID,
+		OwnerPlatformID: p.OwnerPlatformID,
+		ProfileSourceID: p.ProfileSourceID,
+	}
+}
+
+// Networks returns the networks to crawl, skipping the ones without a crawler.
+func (p Param) Networks() []constants.NetworkID {
+	if p.NetworkID != constants.NetworkIDUnknown {
+		return []constants.NetworkID{p.NetworkID}
+	}
+
+	networkIDs := make([]constants.NetworkID, 0)
+
+	for _, networkID := range constants.GetEthereumPlatformNetworks() {
+		if crawler.ByNetwork(networkID) != nil {
+			networkIDs = append(networkIDs, networkID)
+		}
+	}
+
+	return networkIDs
+}
+
+// accountKey identifies the account for deduplication, the limit and the timestamp do not matter
+func (p Param) accountKey() string {
+	return cache.ConstructKey(
+		accountKeyPrefix,
+		p.Identity,
+		p.PlatformID.Symbol().String(),
+		p.NetworkID.Symbol().String(),
+		p.OwnerID,
+		p.OwnerPlatformID.Symbol().String(),
+		p.ProfileSourceID.Name().String(),
+	)
+}
+
+type NetworkResult struct {
+	Network constants.NetworkSymbol `json:"network"`
+	Error   string                  `json:"error,omitempty"`
+	Class   Class                   `json:"class,omitempty"`
+}
+
+type Job struct {
+	ID       string          `json:"id"`
+	Status   Status          `json:"status"`
+	Param    Param           `json:"param"`
+	Networks []NetworkResult `json:"networks,omitempty"`
+	// Attempts counts the runs of the job, a retry only runs the networks failed in the last attempt
+	Attempts int   `json:"attempts"`
+	Class    Class `json:"class,omitempty"`
+	// Priority is interactive while the hub waits for the job, its retries run in the background
+	Priority      crawler.Priority `json:"priority"`
+	CreatedAt     time.Time        `json:"created_at"`
+	StartedAt     *time.Time       `json:"started_at,omitempty"`
+	FinishedAt    *time.Time       `json:"finished_at,omitempty"`
+	NextAttemptAt *time.Time       `json:"next_attempt_at,omitempty"`
+}
+
+// Finished tells whether the last attempt of the job has finished, a retrying job may run again.
+func (j *Job) Finished() bool {
+	switch j.Status {
+	case StatusSucceeded, StatusFailed, StatusRetrying, StatusDead:
+		return true
+	default:
+		return false
+	}
+}
+
+// networks returns the networks to run in the next attempt
+func (j *Job) networks() []constants.NetworkID {
+	if len(j.Networks) == 0 {
+		return j.Param.Networks()
+	}
+
+	networkIDs := make([]constants.NetworkID, 0, len(j.Networks))
+
+	for _, result := range j.Networks {
+		if result.Error != "" && result.Class != ClassUnsupported {
+			networkIDs = append(networkIDs, result.Network.ID())
+		}
+	}
+
+	return networkIDs
+}
+
+// merge keeps the results of an attempt, a retry only has the results of the networks it has run
+func (j *Job) merge(results []NetworkResult) {
+	if len(j.Networks) == 0 {
+		j.Networks = results
+
+		return
+	}
+
+	for _, result := range results {
+		for i := range j.Networks {
+			if j.Networks[i].Network == result.Network {
+				j.Networks[i] = result
+			}
+		}
+	}
+}
+
+func jobKey(id string) string {
+	return cache.ConstructKey(jobKeyPrefix, id)
+}
+
+// Workers returns the number of workers of the pool of an indexer, the pool is disabled if it is negative,
+// e.g. on the indexers only serving the API while others run the jobs.
+func Workers() int {
+	if workers := config.Config.Indexer.Jobs.Workers; workers != 0 {
+		return workers
+	}
+
+	return DefaultWorkers
+}
+
+func dedupWindow() time.Duration {
+	if window := config.Config.Indexer.Jobs.DedupWindow; window > 0 {
+		return window
+	}
+
+	return DefaultDedupWindow
+}
+
+func retention() time.Duration {
+	if retention := config.Config.Indexer.Jobs.Retention; retention > 0 {
+		return retention
+	}
+
+	return DefaultRetention
+}
+
+// Enqueue returns the job of the account if it is still queued, running,
+// or finished within the dedup window, otherwise a new job is added to the stream.
+// A queued job is raised to the priority if it is interactive.
+func Enqueue(ctx context.Context, param Param, priority crawler.Priority) (*Job, error) {
+	job, existing, err := claim(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		if existing.Status == StatusQueued && priority == crawler.PriorityInteractive &&
+			existing.Priority != crawler.PriorityInteractive {
+			existing.Priority = priority
+
+			if err := save(ctx, existing); err != nil {
+				return nil, err
+			}
+		}
+
+		return existing, nil
+	}
+
+	job.Priority = priority
+
+	if err := save(ctx, job); err != nil {
+		return nil, err
+	}
+
+	if err := add(ctx, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// Retry takes over a crawl failed outside of the jobs, e.g. of `GET /item`, as the first attempt of a job.
+// Nothing is done if the account already has a job.
+func Retry(ctx context.Context, param Param, crawlErr error) (*Job, error) {
+	job, existing, err := claim(ctx, param)
+	if err != nil || existing != nil {
+		return existing, err
+	}
+
+	now := time.Now()
+	job.Attempts = 1
+	job.StartedAt = &now
+	job.Networks = []NetworkResult{{
+		Network: param.NetworkID.Symbol(),
+		Error:   crawlErr.Error(),
+		Class:   Classify(crawlErr),
+	}}
+
+	if err := conclude(ctx, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// claim returns a new job of the account if it has none, otherwise its existing job
+func claim(ctx context.Context, param Param) (*Job, *Job, error) {
+	job := &Job{
+		ID:        uuid.NewString(),
+		Status:    StatusQueued,
+		Param:     param,
+		Priority:  crawler.PriorityBackground,
+		CreatedAt: time.Now(),
+	}
+
+	// The account key lives as long as the job until the job finishes, see conclude
+	accountKey := param.accountKey()
+
+	added, err := cache.GetRedisClient().SetNX(ctx, accountKey, job.ID, retention()).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !added {
+		existingID, err := cache.GetRaw(ctx, accountKey)
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return nil, nil, err
+		}
+
+		existing, err := Get(ctx, existingID)
+		if err == nil {
+			return nil, existing, nil
+		}
+
+		if !errors.Is(err, ErrNotFound) {
+			return nil, nil, err
+		}
+
+		// The job has expired before its account key, e.g. the worker has gone
+		if err := cache.SetRaw(ctx, accountKey, job.ID, retention()); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return job, nil, nil
+}
+
+// add puts the job in the stream for the workers
+func add(ctx context.Context, job *Job) error {
+	if err := cache.GetRedisClient().XAdd(ctx, &redis.XAddArgs{
+		Stream: StreamKey,
+		Values: map[string]interface{}{"job_id": job.ID},
+	}).Err(); err != nil {
+		return fmt.Errorf("add job %s to the stream: %w", job.ID, err)
+	}
+
+	return nil
+}
+
+// Get returns ErrNotFound if the job does not exist or its retention has passed.
+func Get(ctx context.Context, id string) (*Job, error) {
+	job := &Job{}
+
+	if err := cache.Get(ctx, jobKey(id), job); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// save keeps a dead job until it is requeued
+func save(ctx context.Context, job *Job) error {
+	if job.Status == StatusDead {
+		return cache.Set(ctx, jobKey(job.ID), job, 0)
+	}
+
+	return cache.Set(ctx, jobKey(job.ID), job, retention())
+}
@@ -0,0 +1,87 @@
+package jobs_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/crawler"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/jobs"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/cache"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnqueue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	identity := uuid.NewString()
+
+	// No crawler serves Arbitrum, the job fails without touching the database
+	param := jobs.Param{
+		Identity:        identity,
+		PlatformID:      constants.PlatformIDEthereum,
+		NetworkID:       constants.NetworkIDArbitrum,
+		OwnerID:         identity,
+		OwnerPlatformID: constants.PlatformIDEthereum,
+	}
+
+	job, err := jobs.Enqueue(ctx, param, crawler.PriorityBackground)
+	assert.Nil(t, err)
+	assert.Equal(t, jobs.StatusQueued, job.Status)
+	assert.Equal(t, crawler.PriorityBackground, job.Priority)
+
+	// The queued job is raised to the priority of a caller waiting for it
+	duplicate, err := jobs.Enqueue(ctx, param, crawler.PriorityInteractive)
+	assert.Nil(t, err)
+	assert.Equal(t, job.ID, duplicate.ID)
+	assert.Equal(t, crawler.PriorityInteractive, duplicate.Priority)
+
+	// A worker reads the message and stops, the pool claims it once it is idle
+	client := cache.GetRedisClient()
+
+	if err := client.XGroupCreateMkStream(ctx, jobs.StreamKey, jobs.GroupName, "0").Err(); err != nil {
+		assert.Contains(t, err.Error(), "BUSYGROUP")
+	}
+
+	streams, err := client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    jobs.GroupName,
+		Consumer: "stopped",
+		Streams:  []string{jobs.StreamKey, ">"},
+		Count:    100,
+		Block:    -1,
+	}).Result()
+	assert.Nil(t, err)
+	assert.NotEmpty(t, streams)
+
+	pool := jobs.NewPool(1)
+	pool.ClaimIdle = 200 * time.Millisecond
+
+	go func() {
+		_ = pool.Run(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		job, err = jobs.Get(ctx, job.ID)
+
+		return err == nil && job.Finished()
+	}, 10*time.Second, 100*time.Millisecond)
+
+	assert.Equal(t, jobs.StatusFailed, job.Status)
+	assert.Len(t, job.Networks, 1)
+	assert.Equal(t, constants.NetworkSymbolArbitrum, job.Networks[0].Network)
+	assert.NotEmpty(t, job.Networks[0].Error)
+
+	// The messages are deleted once they are done
+	assert.Eventually(t, func() bool {
+		length, err := client.XLen(ctx, jobs.StreamKey).Result()
+
+		return err == nil && length == 0
+	}, 10*time.Second, 100*time.Millisecond)
+
+	_, err = jobs.Get(ctx, uuid.NewString())
+	assert.ErrorIs(t, err, jobs.ErrNotFound)
+}
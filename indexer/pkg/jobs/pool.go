@@ -0,0 +1,308 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/crawler"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/crawler_handler"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/cache"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// readBlock is how long a worker waits for a job before checking whether the pool is stopped
+	readBlock = 5 * time.Second
+	// promoteInterval is how often the jobs due for a retry are put back in the stream
+	promoteInterval = time.Second
+	// DefaultClaimIdle is how long a message stays pending before another worker takes it over
+	DefaultClaimIdle = time.Minute
+)
+
+// ErrAbandoned is the error of the networks of an attempt whose worker has stopped during it.
+var ErrAbandoned = errors.New("the worker has stopped during the attempt")
+
+// Pool runs the jobs of the stream, the pools of all the indexers share the consumer group.
+// The worker of a running job claims its message again every quarter of ClaimIdle, so that it is not idle.
+type Pool struct {
+	Workers   int
+	Consumer  string
+	ClaimIdle time.Duration
+}
+
+func NewPool(workers int) *Pool {
+	hostname, _ := os.Hostname()
+
+	return &Pool{
+		Workers:   workers,
+		Consumer:  fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		ClaimIdle: DefaultClaimIdle,
+	}
+}
+
+// Run blocks until ctx is done and the running jobs have stopped.
+func (p *Pool) Run(ctx context.Context) error {
+	if err := cache.GetRedisClient().XGroupCreateMkStream(ctx, StreamKey, GroupName, "0").Err(); err != nil &&
+		!strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("create consumer group: %w", err)
+	}
+
+	logger.Infof("jobs: %d workers of %s started", p.Workers, p.Consumer)
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		p.schedule(ctx)
+	}()
+
+	for i := 0; i < p.Workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			p.work(ctx)
+		}()
+	}
+
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+func (p *Pool) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		// The messages left pending by stopped workers come first
+		messages, err := p.claim(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Errorf("jobs: claim pending messages error: %v", err)
+		}
+
+		if len(messages) > 0 {
+			for _, message := range messages {
+				p.process(ctx, message)
+			}
+
+			continue
+		}
+
+		streams, err := cache.GetRedisClient().XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    GroupName,
+			Consumer: p.Consumer,
+			Streams:  []string{StreamKey, ">"},
+			Count:    1,
+			Block:    readBlock,
+		}).Result()
+		if err != nil {
+			if !errors.Is(err, redis.Nil) && ctx.Err() == nil {
+				logger.Errorf("jobs: read stream error: %v", err)
+
+				time.Sleep(time.Second)
+			}
+
+			continue
+		}
+
+		for _, stream := range streams {
+			for _, message := range stream.Messages {
+				p.process(ctx, message)
+			}
+		}
+	}
+}
+
+func (p *Pool) schedule(ctx context.Context) {
+	ticker := time.NewTicker(promoteInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := promote(ctx); err != nil && ctx.Err() == nil {
+				logger.Errorf("jobs: promote delayed jobs error: %v", err)
+			}
+		}
+	}
+}
+
+func (p *Pool) process(ctx context.Context, message redis.XMessage) {
+	// The message is done with whatever happens to the job, a job is not run twice
+	defer p.done(message)
+
+	id, _ := message.Values["job_id"].(string)
+
+	job, err := Get(ctx, id)
+	if err != nil {
+		logger.Errorf("jobs: get job %s of message %s error: %v", id, message.ID, err)
+
+		return
+	}
+
+	switch job.Status {
+	case StatusQueued:
+	case StatusRunning:
+		// The message has been claimed from a stopped worker, running the job again might stop this one as well
+		if err := abandon(context.Background(), job); err != nil {
+			logger.Errorf("jobs: abandon job %s error: %v", job.ID, err)
+		}
+
+		logger.Warnf("jobs: attempt %d of job %s abandoned, the job is %s", job.Attempts, job.ID, job.Status)
+
+		return
+	default:
+		// The attempt has concluded but its message has not been acked
+		return
+	}
+
+	heartbeatCtx, stopHeartbeat := context.WithCancel(ctx)
+	defer stopHeartbeat()
+
+	go p.heartbeat(heartbeatCtx, message)
+
+	startedAt := time.Now()
+	job.Status = StatusRunning
+	job.StartedAt = &startedAt
+	job.Attempts++
+
+	if err := save(ctx, job); err != nil {
+		logger.Errorf("jobs: save job %s error: %v", job.ID, err)
+	}
+
+	job.merge(run(crawler.WithPriority(ctx, job.Priority), job.Param, job.networks()))
+
+	// The job is concluded even if the pool is stopping
+	if err := conclude(context.Background(), job); err != nil {
+		logger.Errorf("jobs: conclude job %s error: %v", job.ID, err)
+	}
+
+	logger.Infof("jobs: attempt %d of job %s %s in %s", job.Attempts, job.ID, job.Status, time.Since(startedAt))
+}
+
+// claim takes over a message idle for ClaimIdle. It does what XAUTOCLAIM does,
+// whose reply of Redis 7 is not understood by the client.
+func (p *Pool) claim(ctx context.Context) ([]redis.XMessage, error) {
+	client := cache.GetRedisClient()
+
+	pending, err := client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: StreamKey,
+		Group:  GroupName,
+		Idle:   p.ClaimIdle,
+		Start:  "-",
+		End:    "+",
+		Count:  1,
+	}).Result()
+	if err != nil || len(pending) == 0 {
+		return nil, err
+	}
+
+	// Another worker may have claimed it meanwhile, then it is not idle anymore
+	return client.XClaim(ctx, &redis.XClaimArgs{
+		Stream:   StreamKey,
+		Group:    GroupName,
+		Consumer: p.Consumer,
+		MinIdle:  p.ClaimIdle,
+		Messages: []string{pending[0].ID},
+	}).Result()
+}
+
+// done acks and deletes the message, the stream only holds the jobs to run
+func (p *Pool) done(message redis.XMessage) {
+	ctx := context.Background()
+
+	if _, err := cache.GetRedisClient().Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAck(ctx, StreamKey, GroupName, message.ID)
+		pipe.XDel(ctx, StreamKey, message.ID)
+
+		return nil
+	}); err != nil {
+		logger.Errorf("jobs: ack message %s error: %v", message.ID, err)
+	}
+}
+
+// heartbeat claims the message again until ctx is done, which keeps it from being idle
+func (p *Pool) heartbeat(ctx context.Context, message redis.XMessage) {
+	ticker := time.NewTicker(p.ClaimIdle / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := cache.GetRedisClient().XClaimJustID(ctx, &redis.XClaimArgs{
+				Stream:   StreamKey,
+				Group:    GroupName,
+				Consumer: p.Consumer,
+				Messages: []string{message.ID},
+			}).Err(); err != nil && ctx.Err() == nil {
+				logger.Errorf("jobs: heartbeat of message %s error: %v", message.ID, err)
+			}
+		}
+	}
+}
+
+// abandon concludes an attempt whose worker has stopped during it as failed, so that the job
+// is retried later or dead-lettered like any other failure
+func abandon(ctx context.Context, job *Job) error {
+	networkIDs := job.networks()
+	results := make([]NetworkResult, 0, len(networkIDs))
+
+	for _, networkID := range networkIDs {
+		results = append(results, NetworkResult{
+			Network: networkID.Symbol(),
+			Error:   ErrAbandoned.Error(),
+			Class:   ClassOther,
+		})
+	}
+
+	job.merge(results)
+
+	return conclude(ctx, job)
+}
+
+// run crawls the networks at once, each of them is bounded by its own deadline
+func run(ctx context.Context, param Param, networkIDs []constants.NetworkID) []NetworkResult {
+	results := make([]NetworkResult, len(networkIDs))
+
+	var wg sync.WaitGroup
+
+	for i, networkID := range networkIDs {
+		results[i].Network = networkID.Symbol()
+
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			// A crawler must not take down the other jobs of the indexer
+			defer func() {
+				if r := recover(); r != nil {
+					results[i].Error = fmt.Sprintf("crawler panics: %v", r)
+					results[i].Class = ClassOther
+				}
+			}()
+
+			if _, err := crawler_handler.NewGetItemsHandler(param.WorkParam(networkIDs[i])).Excute(ctx); err != nil {
+				results[i].Error = err.Error()
+				results[i].Class = Classify(err)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,240 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/crawler"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/cache"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/httpx"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/metrics"
+	"github.com/go-redis/redis/v8"
+)
+
+// Class tells how likely a failed crawl is to succeed later.
+type Class string
+
+const (
+	ClassRateLimit Class = "rate_limit"
+	ClassServer    Class = "server"
+	ClassParse     Class = "parse"
+	ClassOther     Class = "other"
+	// ClassUnsupported is never retried nor dead-lettered, no crawler serves the network
+	ClassUnsupported Class = "unsupported"
+)
+
+// Policy retries a job MaxAttempts times in total, waiting BaseDelay doubled by every attempt up to MaxDelay.
+type Policy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+var Policies = map[Class]Policy{
+	// Moralis resets its limits every minute
+	ClassRateLimit: {MaxAttempts: 6, BaseDelay: 30 * time.Second, MaxDelay: 10 * time.Minute},
+	ClassServer:    {MaxAttempts: 5, BaseDelay: 10 * time.Second, MaxDelay: 5 * time.Minute},
+	// A response which fails to parse is mostly an error page, it is worth one more try
+	ClassParse:       {MaxAttempts: 2, BaseDelay: time.Minute, MaxDelay: time.Minute},
+	ClassOther:       {MaxAttempts: 3, BaseDelay: 30 * time.Second, MaxDelay: 5 * time.Minute},
+	ClassUnsupported: {MaxAttempts: 1},
+}
+
+// Backoff returns the delay after the attempt, which starts from 1.
+// The delay is jittered between its half and itself so that the jobs failed together are not retried together.
+func (p Policy) Backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	// nolint:gosec // the jitter needs no secure random
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// Classify returns the class of the error, a combined error of several crawls
+// returns the class retried the most among them.
+func Classify(err error) Class {
+	var group interface{ Errors() []error }
+
+	if errors.As(err, &group) {
+		class := ClassUnsupported
+
+		for _, err := range group.Errors() {
+			if current := Classify(err); Policies[current].MaxAttempts > Policies[class].MaxAttempts {
+				class = current
+			}
+		}
+
+		return class
+	}
+
+	var (
+		statusErr *httpx.StatusError
+		netErr    net.Error
+	)
+
+	switch {
+	case errors.Is(err, crawler.ErrUnsupportedNetwork):
+		return ClassUnsupported
+	case errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusTooManyRequests:
+		return ClassRateLimit
+	case errors.As(err, &statusErr) && statusErr.StatusCode >= http.StatusInternalServerError:
+		return ClassServer
+	case errors.Is(err, crawler.ErrParse):
+		return ClassParse
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr):
+		return ClassServer
+	default:
+		return ClassOther
+	}
+}
+
+// conclude sets the status of the job after an attempt, and schedules its retry or dead-letters it
+func conclude(ctx context.Context, job *Job) error {
+	now := time.Now()
+	job.FinishedAt = &now
+	job.NextAttemptAt = nil
+	job.Class = ""
+
+	for _, result := range job.Networks {
+		if result.Error != "" && (job.Class == "" || Policies[result.Class].MaxAttempts > Policies[job.Class].MaxAttempts) {
+			job.Class = result.Class
+		}
+	}
+
+	switch {
+	case job.Class == "":
+		job.Status = StatusSucceeded
+	case job.Class == ClassUnsupported:
+		job.Status = StatusFailed
+	case job.Attempts < Policies[job.Class].MaxAttempts:
+		nextAttemptAt := now.Add(Policies[job.Class].Backoff(job.Attempts))
+		job.Status = StatusRetrying
+		job.NextAttemptAt = &nextAttemptAt
+		// Nobody waits for a retry, it must not take the rate limits of the crawls being waited for
+		job.Priority = crawler.PriorityBackground
+	default:
+		job.Status = StatusDead
+	}
+
+	if err := save(ctx, job); err != nil {
+		return err
+	}
+
+	client := cache.GetRedisClient()
+
+	switch job.Status {
+	case StatusRetrying:
+		metrics.CountCrawlJobFailure(string(job.Class), metrics.OutcomeRetried)
+
+		if err := client.ZAdd(ctx, DelayedKey, &redis.Z{
+			Score:  float64(job.NextAttemptAt.Unix()),
+			Member: job.ID,
+		}).Err(); err != nil {
+			return err
+		}
+
+		// The account stays with the job until its last attempt
+		return expireAccountKey(ctx, job, retention())
+	case StatusDead:
+		metrics.CountCrawlJobFailure(string(job.Class), metrics.OutcomeDeadLettered)
+
+		if err := client.ZAdd(ctx, DeadLetterKey, &redis.Z{
+			Score:  float64(now.Unix()),
+			Member: job.ID,
+		}).Err(); err != nil {
+			return err
+		}
+	}
+
+	return expireAccountKey(ctx, job, dedupWindow())
+}
+
+// expireAccountKey leaves the account key alone if it has been taken by another job
+func expireAccountKey(ctx context.Context, job *Job, expiration time.Duration) error {
+	accountKey := job.Param.accountKey()
+
+	id, err := cache.GetRaw(ctx, accountKey)
+	if errors.Is(err, redis.Nil) || (err == nil && id != job.ID) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return cache.GetRedisClient().Expire(ctx, accountKey, expiration).Err()
+}
+
+// moveDelayed removes the job from the delayed set and adds it to the stream at once,
+// only if it was still in the set. It returns whether the job was moved.
+var moveDelayed = redis.NewScript(`
+if redis.call("ZREM", KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+
+redis.call("XADD", KEYS[2], "*", "job_id", ARGV[1])
+
+return 1
+`)
+
+// promote moves the jobs due for a retry from the delayed set to the stream. A job stays in the set
+// until it is in the stream, a failure leaves it to the next promotion.
+func promote(ctx context.Context) error {
+	client := cache.GetRedisClient()
+
+	ids, err := client.ZRangeByScore(ctx, DelayedKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(time.Now().Unix(), 10),
+		Count: 100,
+	}).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		job, err := Get(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			// The retention of the job has passed, there is nothing left to run
+			if err := client.ZRem(ctx, DelayedKey, id).Err(); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// The workers only run the queued jobs, so the job is queued before it is in the stream
+		job.Status = StatusQueued
+
+		if err := save(ctx, job); err != nil {
+			return err
+		}
+
+		// Only the indexer removing the job from the set adds it to the stream
+		if err := moveDelayed.Run(ctx, client, []string{DelayedKey, StreamKey}, id).Err(); err != nil {
+			return fmt.Errorf("move job %s to the stream: %w", id, err)
+		}
+	}
+
+	return nil
+}
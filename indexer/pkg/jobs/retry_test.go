@@ -0,0 +1,141 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/crawler"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/jobs"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/cache"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/httpx"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/multierr"
+)
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+
+	policy := jobs.Policy{MaxAttempts: 5, BaseDelay: 10 * time.Second, MaxDelay: time.Minute}
+
+	for attempt, delay := range map[int]time.Duration{
+		1: 10 * time.Second,
+		2: 20 * time.Second,
+		3: 40 * time.Second,
+		4: time.Minute,
+		9: time.Minute,
+	} {
+		backoff := policy.Backoff(attempt)
+
+		assert.GreaterOrEqual(t, backoff, delay/2, attempt)
+		assert.LessOrEqual(t, backoff, delay, attempt)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	tooManyRequests := &httpx.StatusError{StatusCode: 429}
+	badGateway := &httpx.StatusError{StatusCode: 502}
+	parseErr := fmt.Errorf("%w: unexpected <", crawler.ErrParse)
+
+	assert.Equal(t, jobs.ClassRateLimit, jobs.Classify(fmt.Errorf("crawler fails while working: %w", tooManyRequests)))
+	assert.Equal(t, jobs.ClassServer, jobs.Classify(badGateway))
+	assert.Equal(t, jobs.ClassServer, jobs.Classify(context.DeadlineExceeded))
+	assert.Equal(t, jobs.ClassParse, jobs.Classify(parseErr))
+	assert.Equal(t, jobs.ClassUnsupported, jobs.Classify(fmt.Errorf("%w: id[4]", crawler.ErrUnsupportedNetwork)))
+	assert.Equal(t, jobs.ClassOther, jobs.Classify(errors.New("unknown")))
+	assert.Equal(t, jobs.ClassOther, jobs.Classify(&httpx.StatusError{StatusCode: 404}))
+
+	// The class retried the most wins
+	assert.Equal(t, jobs.ClassRateLimit, jobs.Classify(fmt.Errorf("crawler fails while working: %w", multierr.Combine(parseErr, tooManyRequests, badGateway))))
+}
+
+func TestDeadLetters(t *testing.T) {
+	ctx := context.Background()
+
+	policy := jobs.Policies[jobs.ClassServer]
+	jobs.Policies[jobs.ClassServer] = jobs.Policy{MaxAttempts: 1}
+
+	t.Cleanup(func() {
+		jobs.Policies[jobs.ClassServer] = policy
+	})
+
+	identity := uuid.NewString()
+
+	job, err := jobs.Retry(ctx, jobs.Param{
+		Identity:        identity,
+		PlatformID:      constants.PlatformIDEthereum,
+		NetworkID:       constants.NetworkIDArbitrum,
+		OwnerID:         identity,
+		OwnerPlatformID: constants.PlatformIDEthereum,
+	}, &httpx.StatusError{StatusCode: 503})
+	assert.Nil(t, err)
+	assert.Equal(t, jobs.StatusDead, job.Status)
+	assert.Equal(t, jobs.ClassServer, job.Class)
+
+	jobList, total, err := jobs.DeadLetters(ctx, 0, 10)
+	assert.Nil(t, err)
+	assert.GreaterOrEqual(t, total, int64(1))
+
+	ids := make([]string, 0, len(jobList))
+	for _, deadJob := range jobList {
+		ids = append(ids, deadJob.ID)
+	}
+
+	assert.Contains(t, ids, job.ID)
+
+	requeued, err := jobs.Requeue(ctx, job.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, jobs.StatusQueued, requeued.Status)
+	assert.Equal(t, 0, requeued.Attempts)
+
+	_, err = jobs.Requeue(ctx, job.ID)
+	assert.NotNil(t, err)
+}
+
+func TestPromote(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	policy := jobs.Policies[jobs.ClassServer]
+	jobs.Policies[jobs.ClassServer] = jobs.Policy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	t.Cleanup(func() {
+		jobs.Policies[jobs.ClassServer] = policy
+	})
+
+	identity := uuid.NewString()
+
+	job, err := jobs.Retry(ctx, jobs.Param{
+		Identity:        identity,
+		PlatformID:      constants.PlatformIDEthereum,
+		NetworkID:       constants.NetworkIDArbitrum,
+		OwnerID:         identity,
+		OwnerPlatformID: constants.PlatformIDEthereum,
+	}, &httpx.StatusError{StatusCode: 503})
+	assert.Nil(t, err)
+	assert.Equal(t, jobs.StatusRetrying, job.Status)
+
+	go func() {
+		_ = jobs.NewPool(1).Run(ctx)
+	}()
+
+	// The pool moves the job from the delayed set to the stream, and runs its second attempt
+	assert.Eventually(t, func() bool {
+		job, err = jobs.Get(ctx, job.ID)
+
+		return err == nil && job.Attempts == 2 && job.Finished()
+	}, 10*time.Second, 100*time.Millisecond)
+
+	// No crawler serves Arbitrum, the second attempt is not retried
+	assert.Equal(t, jobs.StatusFailed, job.Status)
+
+	_, err = cache.GetRedisClient().ZScore(ctx, jobs.DelayedKey, job.ID).Result()
+	assert.ErrorIs(t, err, redis.Nil)
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/crawler"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/util"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
+	"github.com/gin-gonic/gin"
+)
+
+type Crawler struct {
+	Name         string                     `json:"name"`
+	Networks     []constants.NetworkSymbol  `json:"networks"`
+	Platforms    []constants.PlatformSymbol `json:"platforms"`
+	Capabilities []crawler.Capability       `json:"capabilities"`
+}
+
+type GetCrawlerListResponse struct {
+	util.ErrorBase `json:"error"`
+	Crawlers       []Crawler `json:"crawlers"`
+}
+
+// GetCrawlerListHandlerFunc returns the registered crawlers with what they serve.
+func GetCrawlerListHandlerFunc(c *gin.Context) {
+	registrations := crawler.Registrations()
+
+	response := GetCrawlerListResponse{
+		ErrorBase: util.GetErrorBase(util.ErrorCodeSuccess),
+		Crawlers:  make([]Crawler, 0, len(registrations)),
+	}
+
+	for _, registration := range registrations {
+		item := Crawler{
+			Name:         registration.Name,
+			Networks:     make([]constants.NetworkSymbol, 0, len(registration.Networks)),
+			Platforms:    make([]constants.PlatformSymbol, 0, len(registration.Platforms)),
+			Capabilities: registration.Capabilities,
+		}
+
+		for _, networkID := range registration.Networks {
+			item.Networks = append(item.Networks, networkID.Symbol())
+		}
+
+		for _, platformID := range registration.Platforms {
+			item.Platforms = append(item.Platforms, platformID.Symbol())
+		}
+
+		response.Crawlers = append(response.Crawlers, item)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
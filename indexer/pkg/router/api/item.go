@@ -7,6 +7,7 @@ import (
 
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/crawler"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/crawler_handler"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/jobs"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/util"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
@@ -76,8 +77,8 @@ func GetItemHandlerFunc(c *gin.Context) {
 		return
 	}
 
-	// get items from crawler
-	errorBase := getItemsResult(c.Request.Context(), request)
+	// get items from crawler, the caller is waiting for them
+	errorBase := getItemsResult(crawler.WithPriority(c.Request.Context(), crawler.PriorityInteractive), request)
 	response.ErrorBase = errorBase
 
 	if response.ErrorBase.ErrorCode == 0 {
@@ -108,6 +109,7 @@ func GetItemHandlerFunc(c *gin.Context) {
 // }
 
 func getItemsResultFromOneNetwork(
+	ctx context.Context,
 	identity string,
 	platformID constants.PlatformID,
 	networkID constants.NetworkID,
@@ -117,7 +119,7 @@ func getItemsResultFromOneNetwork(
 	ownerPlatformID constants.PlatformID,
 	profileSourceID constants.ProfileSourceID,
 ) util.ErrorBase {
-	getItemHandler := crawler_handler.NewGetItemsHandler(crawler.WorkParam{
+	workParam := crawler.WorkParam{
 		Identity:        identity,
 		PlatformID:      platformID,
 		NetworkID:       networkID,
@@ -126,12 +128,17 @@ func getItemsResultFromOneNetwork(
 		OwnerID:         ownerID,
 		OwnerPlatformID: ownerPlatformID,
 		ProfileSourceID: profileSourceID,
-	})
+	}
 
-	handlerResult, err := getItemHandler.Excute()
+	handlerResult, err := crawler_handler.NewGetItemsHandler(workParam).Excute(ctx)
 	if err != nil {
 		logger.Errorf("get items from crawler error: %s", err.Error())
 
+		// A job retries the crawl, the request may have gone already
+		if _, retryErr := jobs.Retry(context.Background(), jobs.FromWorkParam(workParam), err); retryErr != nil {
+			logger.Errorf("[%s] retry crawl error: %v", identity, retryErr)
+		}
+
 		return util.GetErrorBase(util.ErrorCodeNotFoundData)
 	}
 
@@ -154,8 +161,13 @@ func getItemsResult(ctx context.Context, request GetItemRequest) util.ErrorBase
 	if *request.NetworkID == constants.NetworkIDUnknown {
 		networkIDs := constants.GetEthereumPlatformNetworks()
 		for _, networkID := range networkIDs {
+			// the client has gone, there is no one to crawl the remaining networks for
+			if ctx.Err() != nil {
+				return util.GetErrorBase(util.ErrorCodeNotFoundData)
+			}
+
 			currErrorBase := getItemsResultFromOneNetwork(
-				request.Identity, *request.PlatformID, networkID,
+				ctx, request.Identity, *request.PlatformID, networkID,
 				request.Limit, time.Unix(request.Timestamp, 0),
 				request.OwnerID, *request.OwnerPlatformID, *request.ProfileSourceID,
 			)
@@ -167,7 +179,7 @@ func getItemsResult(ctx context.Context, request GetItemRequest) util.ErrorBase
 		}
 	} else {
 		errorBase = getItemsResultFromOneNetwork(
-			request.Identity, *request.PlatformID, *request.NetworkID,
+			ctx, request.Identity, *request.PlatformID, *request.NetworkID,
 			request.Limit, time.Unix(request.Timestamp, 0),
 			request.OwnerID, *request.OwnerPlatformID, *request.ProfileSourceID,
 		)
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/crawler"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/jobs"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/util"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+type PostJobRequest struct {
+	Identity   string                `form:"proof" binding:"required"`
+	PlatformID *constants.PlatformID `form:"platform_id" binding:"required"`
+	// NetworkID is optional, every network of the Ethereum platform is crawled if omitted
+	NetworkID *constants.NetworkID `form:"network_id"`
+	Limit     int                  `form:"limit"`
+	Timestamp int64                `form:"timestamp"`
+
+	OwnerID         string                     `form:"owner_id" binding:"required"`
+	OwnerPlatformID *constants.PlatformID      `form:"owner_platform_id" binding:"required"`
+	ProfileSourceID *constants.ProfileSourceID `form:"profile_source_id" binding:"required"`
+
+	// Priority is interactive if the caller waits for the job, the job is run in the background otherwise
+	Priority crawler.Priority `form:"priority"`
+}
+
+type JobResponse struct {
+	util.ErrorBase `json:"error"`
+	Job            *jobs.Job `json:"job,omitempty"`
+}
+
+// PostJobHandlerFunc queues a crawl of the account, the job of an account already being crawled is returned instead.
+func PostJobHandlerFunc(c *gin.Context) {
+	request := PostJobRequest{}
+
+	if err := c.ShouldBind(&request); err != nil {
+		response := JobResponse{ErrorBase: util.GetErrorBase(util.ErrorCodeParameterError)}
+		response.ErrorBase.ErrorMsg += ": " + util.ErrorMsg(err.Error())
+
+		c.JSON(http.StatusOK, response)
+
+		return
+	}
+
+	param := jobs.Param{
+		Identity:        request.Identity,
+		PlatformID:      *request.PlatformID,
+		NetworkID:       constants.NetworkIDUnknown,
+		Limit:           request.Limit,
+		Timestamp:       time.Unix(request.Timestamp, 0),
+		OwnerID:         request.OwnerID,
+		OwnerPlatformID: *request.OwnerPlatformID,
+		ProfileSourceID: *request.ProfileSourceID,
+	}
+
+	if param.Limit == 0 {
+		param.Limit = 100
+	}
+
+	if request.Timestamp == 0 {
+		param.Timestamp = time.Now()
+	}
+
+	var paramErrMsg string
+
+	if !constants.IsValidPlatformSymbol(string(param.PlatformID.Symbol())) {
+		paramErrMsg += "platform_id is invalid; "
+	}
+
+	if request.NetworkID != nil {
+		param.NetworkID = *request.NetworkID
+
+		if !constants.IsValidNetworkName(string(param.NetworkID.Symbol())) {
+			paramErrMsg += "network_id is invalid; "
+		}
+	}
+
+	if request.Priority == "" {
+		request.Priority = crawler.PriorityBackground
+	}
+
+	if request.Priority != crawler.PriorityInteractive && request.Priority != crawler.PriorityBackground {
+		paramErrMsg += "priority is invalid; "
+	}
+
+	if paramErrMsg != "" {
+		response := JobResponse{ErrorBase: util.GetErrorBase(util.ErrorCodeParameterError)}
+		response.ErrorBase.ErrorMsg += ": " + util.ErrorMsg(paramErrMsg)
+
+		c.JSON(http.StatusOK, response)
+
+		return
+	}
+
+	job, err := jobs.Enqueue(c.Request.Context(), param, request.Priority)
+	if err != nil {
+		logger.Errorf("[%s] enqueue job error: %v", request.Identity, err)
+
+		c.JSON(http.StatusOK, JobResponse{ErrorBase: util.GetErrorBase(util.ErrorCodeGetDataError)})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, JobResponse{
+		ErrorBase: util.GetErrorBase(util.ErrorCodeSuccess),
+		Job:       job,
+	})
+}
+
+// GetJobHandlerFunc returns the status of a job, and the result of every network once it has finished.
+func GetJobHandlerFunc(c *gin.Context) {
+	job, err := jobs.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, jobs.ErrNotFound) {
+			c.JSON(http.StatusOK, JobResponse{ErrorBase: util.GetErrorBase(util.ErrorCodeNotFoundData)})
+
+			return
+		}
+
+		logger.Errorf("get job %s error: %v", c.Param("id"), err)
+
+		c.JSON(http.StatusOK, JobResponse{ErrorBase: util.GetErrorBase(util.ErrorCodeGetDataError)})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, JobResponse{
+		ErrorBase: util.GetErrorBase(util.ErrorCodeSuccess),
+		Job:       job,
+	})
+}
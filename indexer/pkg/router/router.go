@@ -5,15 +5,24 @@ import (
 
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/router/api"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/router/monitor"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/cache"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/config"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/health"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/metrics"
 	"github.com/getsentry/sentry-go"
 	sentrygin "github.com/getsentry/sentry-go/gin"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func InitRouter() *gin.Engine {
 	r := gin.New()
 
+	if err := r.SetTrustedProxies(config.Config.Indexer.Server.TrustedProxies); err != nil {
+		panic(err)
+	}
+
 	// Apply middlewares
 	r.Use(gin.Recovery())
 
@@ -30,6 +39,8 @@ func InitRouter() *gin.Engine {
 		}))
 	}
 
+	r.Use(metrics.Middleware())
+
 	// === Error handler ===
 	r.NoRoute(func(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{
@@ -44,7 +55,16 @@ func InitRouter() *gin.Engine {
 	})
 
 	r.GET("/item", api.GetItemHandlerFunc)
+	r.GET("/crawlers", api.GetCrawlerListHandlerFunc)
+	r.POST("/jobs", api.PostJobHandlerFunc)
+	r.GET("/jobs/:id", api.GetJobHandlerFunc)
 	r.GET("/debug/statsviz/*filepath", monitor.Statsviz)
+	r.GET("/metrics", metrics.Handler())
+	r.GET("/healthz", gin.WrapH(health.LivenessHandler()))
+	r.GET("/readyz", gin.WrapH(health.ReadinessHandler(
+		health.Postgres(func() *gorm.DB { return database.DB }),
+		health.Redis(cache.GetRedisClient),
+	)))
 
 	return r
 }
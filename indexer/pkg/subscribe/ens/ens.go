@@ -110,7 +110,7 @@ func (s *Ens) GetOwnerFeed(owner string) {
 			OwnerID:    owner,
 		})
 
-		_, err = getItemHandler.Excute()
+		_, err = getItemHandler.Excute(context.Background())
 		if err != nil {
 			logger.Errorf("SubscribeEns: get item error, %v", err)
 
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"time"
 
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/indexer/pkg/crawler"
@@ -30,7 +31,7 @@ func getOwnerFeed(instance rss3uri.Instance, owner string) {
 			OwnerID:    owner,
 		})
 
-		_, err = getItemHandler.Excute()
+		_, err = getItemHandler.Excute(context.Background())
 		if err != nil {
 			logger.Errorf("subscribe.script:: get item error, %v", err)
 
@@ -34,6 +34,7 @@ var (
 		ErrorCodeParameterError:      ErrorMsgParameterError,
 		ErrorCodeNotFoundData:        ErrorMsgNotFoundData,
 		ErrorCodeNotSupportedNetwork: ErrorMsgNotSupportedNetwork,
+		ErrorCodeGetDataError:        ErrorMsgGetDataError,
 	}
 )
 
@@ -8,6 +8,7 @@ import (
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/common"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/metrics"
 )
 
 var keyOffset = make(map[string]int)
@@ -60,6 +61,8 @@ func GetCrawlerMetadata(identity string, platformID constants.PlatformID) (int64
 		return 0, fmt.Errorf("crawler metadata not found")
 	}
 
+	metrics.SetCrawlerCheckpoint(identity, metadata.LastBlock)
+
 	return metadata.LastBlock, nil
 }
 
@@ -78,5 +81,7 @@ func SetCrawlerMetadata(
 		return fmt.Errorf("set last position error: %s", err)
 	}
 
+	metrics.SetCrawlerCheckpoint(instance, fromHeight)
+
 	return nil
 }
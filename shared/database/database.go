@@ -10,6 +10,7 @@ import (
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/config"
 	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/constants"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/metrics"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -50,6 +51,15 @@ END;
 
 var DB *gorm.DB
 
+const (
+	// NoteChannel is notified with the comma separated owners of created or updated notes
+	NoteChannel = "note_upserted"
+	// AssetChannel is notified with the comma separated owners of created or updated assets
+	AssetChannel = "asset_upserted"
+	// maxNotifyPayload stays below the 8000 bytes limit of pg_notify
+	maxNotifyPayload = 7900
+)
+
 func Setup() error {
 	db, err := gorm.Open(postgres.New(postgres.Config{
 		// TODO Refactor config package
@@ -90,6 +100,13 @@ func Setup() error {
 	//	&model.Account{},
 	//	&model.Asset{},
 	//	&model.Note{},
+	//	&model.Link{},
+	//	&model.ActivityPubFollower{},
+	//	&model.ActivityPubDelivery{},
+	//	&model.Webhook{},
+	//	&model.WebhookDelivery{},
+	//	&model.APIKey{},
+	//	&model.SearchCursor{},
 	//	&model.CrawlerMetadata{},
 	// 	&model.Cache{},
 	// ); err != nil {
@@ -111,7 +128,14 @@ func CreateNote(db *gorm.DB, note *model.Note, updateAll bool) (*model.Note, err
 	note.Identifier = strings.ToLower(note.Identifier)
 	note.Owner = strings.ToLower(note.Owner)
 
-	if err := db.Model(note).Clauses(NewCreateClauses(updateAll, true, true)...).Create(note).Error; err != nil {
+	result := db.Model(note).Clauses(NewCreateClauses(updateAll, true, true)...).Create(note)
+	if err := result.Error; err != nil {
+		return nil, err
+	}
+
+	metrics.CountUpserts(note.TableName(), result.RowsAffected)
+
+	if err := NotifyNotes(db, []model.Note{*note}); err != nil {
 		return nil, err
 	}
 
@@ -122,7 +146,14 @@ func CreateAsset(db *gorm.DB, asset *model.Asset, updateAll bool) (*model.Asset,
 	asset.Identifier = strings.ToLower(asset.Identifier)
 	asset.Owner = strings.ToLower(asset.Owner)
 
-	if err := db.Clauses(NewCreateClauses(updateAll, true, true)...).Create(asset).Error; err != nil {
+	result := db.Clauses(NewCreateClauses(updateAll, true, true)...).Create(asset)
+	if err := result.Error; err != nil {
+		return nil, err
+	}
+
+	metrics.CountUpserts(asset.TableName(), result.RowsAffected)
+
+	if err := NotifyAssets(db, []model.Asset{*asset}); err != nil {
 		return nil, err
 	}
 
@@ -139,13 +170,65 @@ func CreateNotes(db *gorm.DB, notes []model.Note, updateAll bool) ([]model.Note,
 		}
 	}
 
-	if err := db.Clauses(NewCreateClauses(updateAll, true, true)...).Create(&notes).Error; err != nil {
+	result := db.Clauses(NewCreateClauses(updateAll, true, true)...).Create(&notes)
+	if err := result.Error; err != nil {
+		return nil, err
+	}
+
+	metrics.CountUpserts(model.Note{}.TableName(), result.RowsAffected)
+
+	if err := NotifyNotes(db, notes); err != nil {
 		return nil, err
 	}
 
 	return notes, nil
 }
 
+// NotifyNotes tells the listeners of NoteChannel which owners have new notes,
+// inside a transaction the notification is only delivered once it commits.
+func NotifyNotes(db *gorm.DB, notes []model.Note) error {
+	owners := make([]string, 0, len(notes))
+	for _, note := range notes {
+		owners = append(owners, note.Owner)
+	}
+
+	return notifyOwners(db, NoteChannel, owners)
+}
+
+// NotifyAssets tells the listeners of AssetChannel which owners have new assets, see NotifyNotes.
+func NotifyAssets(db *gorm.DB, assets []model.Asset) error {
+	owners := make([]string, 0, len(assets))
+	for _, asset := range assets {
+		owners = append(owners, asset.Owner)
+	}
+
+	return notifyOwners(db, AssetChannel, owners)
+}
+
+func notifyOwners(db *gorm.DB, channel string, owners []string) error {
+	if len(owners) == 0 {
+		return nil
+	}
+
+	uniqueOwners := make([]string, 0, 1)
+	ownerSet := map[string]struct{}{}
+
+	for _, owner := range owners {
+		if _, exists := ownerSet[owner]; !exists {
+			ownerSet[owner] = struct{}{}
+			uniqueOwners = append(uniqueOwners, owner)
+		}
+	}
+
+	// An empty payload wakes up the listeners of all owners
+	payload := strings.Join(uniqueOwners, ",")
+	if len(payload) > maxNotifyPayload {
+		payload = ""
+	}
+
+	return db.Exec("SELECT pg_notify(?, ?)", channel, payload).Error
+}
+
 func CreateNotesDoNothing(db *gorm.DB, notes []model.Note) ([]model.Note, error) {
 	for i := range notes {
 		notes[i].Identifier = strings.ToLower(notes[i].Identifier)
@@ -156,7 +239,14 @@ func CreateNotesDoNothing(db *gorm.DB, notes []model.Note) ([]model.Note, error)
 		}
 	}
 
-	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&notes).Error; err != nil {
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&notes)
+	if err := result.Error; err != nil {
+		return nil, err
+	}
+
+	metrics.CountUpserts(model.Note{}.TableName(), result.RowsAffected)
+
+	if err := NotifyNotes(db, notes); err != nil {
 		return nil, err
 	}
 
@@ -173,13 +263,39 @@ func CreateAssets(db *gorm.DB, assets []model.Asset, updateAll bool) ([]model.As
 		}
 	}
 
-	if err := db.Clauses(NewCreateClauses(updateAll, true, true)...).Create(&assets).Error; err != nil {
+	result := db.Clauses(NewCreateClauses(updateAll, true, true)...).Create(&assets)
+	if err := result.Error; err != nil {
+		return nil, err
+	}
+
+	metrics.CountUpserts(model.Asset{}.TableName(), result.RowsAffected)
+
+	if err := NotifyAssets(db, assets); err != nil {
 		return nil, err
 	}
 
 	return assets, nil
 }
 
+// CreateLinks upserts links, the identifiers are lowercased to match the owners of notes.
+// The follows are crawled from Crossbell, the links of Lens are not indexed yet.
+func CreateLinks(db *gorm.DB, links []model.Link, updateAll bool) ([]model.Link, error) {
+	for i := range links {
+		links[i].Origin = strings.ToLower(links[i].Origin)
+		links[i].Target = strings.ToLower(links[i].Target)
+
+		if links[i].Metadata == nil {
+			links[i].Metadata = []byte("{}")
+		}
+	}
+
+	if err := db.Clauses(NewCreateClauses(updateAll, true, false)...).Create(&links).Error; err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
 func DeleteAsset(db *gorm.DB, asset *model.Asset) (*model.Asset, error) {
 	if err := db.Clauses(clause.Returning{}).Delete(&asset).Error; err != nil {
 		return nil, err
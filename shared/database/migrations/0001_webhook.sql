@@ -0,0 +1,45 @@
+-- The webhooks and the queue of their deliveries
+CREATE TABLE IF NOT EXISTS webhook
+(
+    id                uuid PRIMARY KEY,
+    url               text        NOT NULL,
+    secret            text        NOT NULL DEFAULT '',
+    owners            text[],
+    tags              text[],
+    networks          text[],
+    sources           text[],
+    cursor_created_at timestamptz NOT NULL DEFAULT now(),
+    cursor_identifier text        NOT NULL DEFAULT '',
+    created_at        timestamptz NOT NULL DEFAULT now(),
+    updated_at        timestamptz NOT NULL DEFAULT now(),
+    deleted_at        timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS index_webhook_owners ON webhook USING gin (owners);
+CREATE INDEX IF NOT EXISTS idx_webhook_created_at ON webhook (created_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_updated_at ON webhook (updated_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deleted_at ON webhook (deleted_at);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery
+(
+    id                   bigserial PRIMARY KEY,
+    webhook_id           uuid        NOT NULL,
+    event                text        NOT NULL,
+    note_identifier      text,
+    payload              jsonb       NOT NULL,
+    status               text        NOT NULL,
+    attempts             bigint      NOT NULL DEFAULT 0,
+    next_attempt_at      timestamptz NOT NULL,
+    response_status_code bigint      NOT NULL DEFAULT 0,
+    response_latency     bigint      NOT NULL DEFAULT 0,
+    error                text        NOT NULL DEFAULT '',
+    created_at           timestamptz NOT NULL DEFAULT now(),
+    updated_at           timestamptz NOT NULL DEFAULT now(),
+    deleted_at           timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS index_webhook_delivery_note ON webhook_delivery (webhook_id, note_identifier);
+CREATE INDEX IF NOT EXISTS index_webhook_delivery_status_next_attempt_at ON webhook_delivery (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_created_at ON webhook_delivery (created_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_updated_at ON webhook_delivery (updated_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_deleted_at ON webhook_delivery (deleted_at);
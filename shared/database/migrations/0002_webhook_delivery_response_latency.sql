@@ -0,0 +1,3 @@
+-- The response bodies of the webhooks are no longer kept, only how long they took
+ALTER TABLE webhook_delivery ADD COLUMN IF NOT EXISTS response_latency bigint NOT NULL DEFAULT 0;
+ALTER TABLE webhook_delivery DROP COLUMN IF EXISTS response_body;
@@ -0,0 +1,17 @@
+-- The fediverse actors following the account instances
+CREATE TABLE IF NOT EXISTS activitypub_follower
+(
+    instance          text,
+    actor             text,
+    inbox             text        NOT NULL,
+    cursor_created_at timestamptz NOT NULL DEFAULT now(),
+    cursor_identifier text        NOT NULL DEFAULT '',
+    created_at        timestamptz NOT NULL DEFAULT now(),
+    updated_at        timestamptz NOT NULL DEFAULT now(),
+    deleted_at        timestamptz,
+    PRIMARY KEY (instance, actor)
+);
+
+CREATE INDEX IF NOT EXISTS idx_activitypub_follower_created_at ON activitypub_follower (created_at);
+CREATE INDEX IF NOT EXISTS idx_activitypub_follower_updated_at ON activitypub_follower (updated_at);
+CREATE INDEX IF NOT EXISTS idx_activitypub_follower_deleted_at ON activitypub_follower (deleted_at);
@@ -0,0 +1,26 @@
+-- The notes are delivered to the followers from the cursor on
+ALTER TABLE activitypub_follower ADD COLUMN IF NOT EXISTS cursor_created_at timestamptz NOT NULL DEFAULT now();
+ALTER TABLE activitypub_follower ADD COLUMN IF NOT EXISTS cursor_identifier text NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS activitypub_delivery
+(
+    id                   bigserial PRIMARY KEY,
+    instance             text        NOT NULL,
+    inbox                text        NOT NULL,
+    note_identifier      text,
+    activity             jsonb       NOT NULL,
+    status               text        NOT NULL,
+    attempts             bigint      NOT NULL DEFAULT 0,
+    next_attempt_at      timestamptz NOT NULL,
+    response_status_code bigint      NOT NULL DEFAULT 0,
+    error                text        NOT NULL DEFAULT '',
+    created_at           timestamptz NOT NULL DEFAULT now(),
+    updated_at           timestamptz NOT NULL DEFAULT now(),
+    deleted_at           timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS index_activitypub_delivery_note ON activitypub_delivery (inbox, note_identifier);
+CREATE INDEX IF NOT EXISTS index_activitypub_delivery_status_next_attempt_at ON activitypub_delivery (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_activitypub_delivery_created_at ON activitypub_delivery (created_at);
+CREATE INDEX IF NOT EXISTS idx_activitypub_delivery_updated_at ON activitypub_delivery (updated_at);
+CREATE INDEX IF NOT EXISTS idx_activitypub_delivery_deleted_at ON activitypub_delivery (deleted_at);
@@ -0,0 +1,8 @@
+-- The indexes of the mime type and creation date filters, built without locking the tables.
+-- CONCURRENTLY does not run in a transaction, so the statements must be run one by one, e.g. psql -f.
+-- A build which fails leaves an invalid index behind, drop it before running the file again.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS index_note_attachments ON note4 USING gin (attachments jsonb_path_ops);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS index_note_owner_date_created ON note4 (owner, date_created DESC);
+
+CREATE INDEX CONCURRENTLY IF NOT EXISTS index_asset_attachments ON asset USING gin (attachments jsonb_path_ops);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS index_asset_owner_date_created ON asset (owner, date_created DESC);
@@ -0,0 +1,14 @@
+-- Search documents are indexed from the committed rows, following updated_at from the cursor on.
+-- CONCURRENTLY does not run in a transaction, so the statements must be run one by one, e.g. psql -f.
+CREATE TABLE IF NOT EXISTS search_cursor
+(
+    kind              text PRIMARY KEY,
+    cursor_updated_at timestamptz NOT NULL DEFAULT now(),
+    cursor_identifier text        NOT NULL DEFAULT '',
+    created_at        timestamptz NOT NULL DEFAULT now(),
+    updated_at        timestamptz NOT NULL DEFAULT now(),
+    deleted_at        timestamptz
+);
+
+CREATE INDEX CONCURRENTLY IF NOT EXISTS index_note_updated_at_identifier ON note4 (updated_at, identifier);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS index_asset_updated_at_identifier ON asset (updated_at, identifier);
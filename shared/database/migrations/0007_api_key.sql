@@ -0,0 +1,18 @@
+-- The API keys of the clients, only the hashes of the keys are stored
+CREATE TABLE IF NOT EXISTS api_key
+(
+    id          uuid PRIMARY KEY,
+    name        text        NOT NULL DEFAULT '',
+    key_hash    text        NOT NULL,
+    crawl_limit bigint      NOT NULL DEFAULT 0,
+    read_limit  bigint      NOT NULL DEFAULT 0,
+    revoked_at  timestamptz,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now(),
+    deleted_at  timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_key_key_hash ON api_key (key_hash);
+CREATE INDEX IF NOT EXISTS idx_api_key_created_at ON api_key (created_at);
+CREATE INDEX IF NOT EXISTS idx_api_key_updated_at ON api_key (updated_at);
+CREATE INDEX IF NOT EXISTS idx_api_key_deleted_at ON api_key (deleted_at);
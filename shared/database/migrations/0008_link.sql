@@ -0,0 +1,25 @@
+-- The links between the instances, e.g. the follows crawled from Crossbell
+CREATE TABLE IF NOT EXISTS link
+(
+    type         bigint,
+    origin       text,
+    target       text,
+    source       bigint,
+    metadata     jsonb       NOT NULL DEFAULT '{}',
+    date_created timestamptz NOT NULL,
+    date_updated timestamptz NOT NULL,
+    created_at   timestamptz NOT NULL DEFAULT now(),
+    updated_at   timestamptz NOT NULL DEFAULT now(),
+    deleted_at   timestamptz,
+    PRIMARY KEY (type, origin, target, source)
+);
+
+CREATE INDEX IF NOT EXISTS index_link_type ON link (type);
+CREATE INDEX IF NOT EXISTS index_link_origin ON link (origin);
+CREATE INDEX IF NOT EXISTS index_link_target ON link (target);
+CREATE INDEX IF NOT EXISTS index_link_source ON link (source);
+CREATE INDEX IF NOT EXISTS index_link_date_created ON link (date_created);
+CREATE INDEX IF NOT EXISTS index_link_date_updated ON link (date_updated);
+CREATE INDEX IF NOT EXISTS idx_link_created_at ON link (created_at);
+CREATE INDEX IF NOT EXISTS idx_link_updated_at ON link (updated_at);
+CREATE INDEX IF NOT EXISTS idx_link_deleted_at ON link (deleted_at);
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/common"
+	"gorm.io/datatypes"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	_ schema.Tabler = &ActivityPubFollower{}
+	_ schema.Tabler = &ActivityPubDelivery{}
+)
+
+// ActivityPubFollower is a fediverse actor following an account instance through the hub.
+type ActivityPubFollower struct {
+	Instance string `gorm:"column:instance;primaryKey"`
+	Actor    string `gorm:"column:actor;primaryKey"`
+	Inbox    string `gorm:"column:inbox"`
+	// The last note enqueued, ordered by created_at and identifier
+	CursorCreatedAt  time.Time `gorm:"column:cursor_created_at"`
+	CursorIdentifier string    `gorm:"column:cursor_identifier"`
+
+	common.Table
+}
+
+func (ActivityPubFollower) TableName() string {
+	return "activitypub_follower"
+}
+
+// ActivityPubDelivery is both the queue and the log of the activities sent to follower inboxes.
+type ActivityPubDelivery struct {
+	ID       int64  `gorm:"column:id;primaryKey;autoIncrement"`
+	Instance string `gorm:"column:instance"`
+	Inbox    string `gorm:"column:inbox;uniqueIndex:index_activitypub_delivery_note"`
+	// NoteIdentifier is null for the replies to followers, e.g. Accept, which are never deduplicated
+	NoteIdentifier     sql.NullString `gorm:"column:note_identifier;uniqueIndex:index_activitypub_delivery_note"`
+	Activity           datatypes.JSON `gorm:"column:activity"`
+	Status             string         `gorm:"column:status;index:index_activitypub_delivery_status_next_attempt_at"`
+	Attempts           int            `gorm:"column:attempts"`
+	NextAttemptAt      time.Time      `gorm:"column:next_attempt_at;index:index_activitypub_delivery_status_next_attempt_at"`
+	ResponseStatusCode int            `gorm:"column:response_status_code"`
+	Error              string         `gorm:"column:error"`
+
+	common.Table
+}
+
+func (ActivityPubDelivery) TableName() string {
+	return "activitypub_delivery"
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"database/sql"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/common"
+	"github.com/google/uuid"
+	"gorm.io/gorm/schema"
+)
+
+var _ schema.Tabler = &APIKey{}
+
+// APIKey identifies a client of the hub, only the hash of the key is stored.
+type APIKey struct {
+	ID      uuid.UUID `gorm:"column:id;type:uuid;primaryKey"`
+	Name    string    `gorm:"column:name"`
+	KeyHash string    `gorm:"column:key_hash;uniqueIndex"`
+	// Requests per minute, the limits of the config are used if zero
+	CrawlLimit int          `gorm:"column:crawl_limit"`
+	ReadLimit  int          `gorm:"column:read_limit"`
+	RevokedAt  sql.NullTime `gorm:"column:revoked_at"`
+
+	common.Table
+}
+
+func (APIKey) TableName() string {
+	return "api_key"
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/common"
+	"gorm.io/datatypes"
+	"gorm.io/gorm/schema"
+)
+
+var _ schema.Tabler = &Link{}
+
+// Link is a directed relation from the origin identifier to the target identifier,
+// e.g. an account following another account or a note commenting on another note.
+type Link struct {
+	Type        int            `gorm:"column:type;primaryKey;index:index_link_type"`
+	Origin      string         `gorm:"column:origin;primaryKey;index:index_link_origin"`
+	Target      string         `gorm:"column:target;primaryKey;index:index_link_target"`
+	Source      int            `gorm:"column:source;primaryKey;index:index_link_source"`
+	Metadata    datatypes.JSON `gorm:"column:metadata;not null;default:'{}'"`
+	DateCreated time.Time      `gorm:"column:date_created;index:index_link_date_created"`
+	DateUpdated time.Time      `gorm:"column:date_updated;index:index_link_date_updated"`
+
+	common.Table
+}
+
+func (Link) TableName() string {
+	return "link"
+}
@@ -15,19 +15,19 @@ type Note struct {
 	Identifier          string         `gorm:"column:identifier;primaryKey"`
 	TransactionHash     string         `gorm:"column:transaction_hash;index:index_note_hash_index"`
 	TransactionLogIndex int            `gorm:"column:transaction_log_index;index:index_note_hash_index"`
-	Owner               string         `gorm:"colum:owner;index:index_note_owner"`
+	Owner               string         `gorm:"colum:owner;index:index_note_owner;index:index_note_owner_date_created,priority:1"`
 	ProfileSourceID     int            `gorm:"colum:profile_source_id;index:index_note_profile_source_id"`
 	RelatedURLs         pq.StringArray `gorm:"column:related_urls;type:text[]"`
 	Tags                pq.StringArray `gorm:"column:tags;type:text[]"`
 	Authors             pq.StringArray `gorm:"column:authors;type:text[]"`
 	Title               string         `gorm:"column:title"`
 	Summary             string         `gorm:"column:summary"`
-	Attachments         datatypes.JSON `gorm:"column:attachments;not null;default:'[]'"`
+	Attachments         datatypes.JSON `gorm:"column:attachments;not null;default:'[]';index:index_note_attachments,type:gin,expression:attachments jsonb_path_ops"`
 	Source              string         `gorm:"column:source;index:index_note_source"`
 	MetadataNetwork     string         `gorm:"column:metadata_network"`
 	MetadataProof       string         `gorm:"column:metadata_proof"`
 	Metadata            datatypes.JSON `gorm:"column:metadata;not null;default:'{}'"`
-	DateCreated         time.Time      `gorm:"column:date_created;index:index_note_date_created"`
+	DateCreated         time.Time      `gorm:"column:date_created;index:index_note_date_created;index:index_note_owner_date_created,priority:2,sort:desc"`
 	DateUpdated         time.Time      `gorm:"column:date_updated;index:index_note_date_updated"`
 
 	common.Table
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/common"
+	"gorm.io/gorm/schema"
+)
+
+var _ schema.Tabler = &SearchCursor{}
+
+// SearchCursor is the last note or asset indexed for search, shared by all the hubs.
+type SearchCursor struct {
+	Kind string `gorm:"column:kind;primaryKey"`
+	// The last row indexed, ordered by updated_at and identifier
+	CursorUpdatedAt  time.Time `gorm:"column:cursor_updated_at"`
+	CursorIdentifier string    `gorm:"column:cursor_identifier"`
+
+	common.Table
+}
+
+func (SearchCursor) TableName() string {
+	return "search_cursor"
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/common"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/datatypes"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	_ schema.Tabler = &Webhook{}
+	_ schema.Tabler = &WebhookDelivery{}
+)
+
+// Webhook receives the notes of its owners matching the optional tags, networks and sources.
+type Webhook struct {
+	ID       uuid.UUID      `gorm:"column:id;type:uuid;primaryKey"`
+	URL      string         `gorm:"column:url"`
+	Secret   string         `gorm:"column:secret"`
+	Owners   pq.StringArray `gorm:"column:owners;type:text[];index:index_webhook_owners,type:gin"`
+	Tags     pq.StringArray `gorm:"column:tags;type:text[]"`
+	Networks pq.StringArray `gorm:"column:networks;type:text[]"`
+	Sources  pq.StringArray `gorm:"column:sources;type:text[]"`
+	// The last note enqueued, ordered by created_at and identifier
+	CursorCreatedAt  time.Time `gorm:"column:cursor_created_at"`
+	CursorIdentifier string    `gorm:"column:cursor_identifier"`
+
+	common.Table
+}
+
+func (Webhook) TableName() string {
+	return "webhook"
+}
+
+// WebhookDelivery is both the queue and the log of the requests sent to a webhook.
+type WebhookDelivery struct {
+	ID        int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	WebhookID uuid.UUID `gorm:"column:webhook_id;type:uuid;uniqueIndex:index_webhook_delivery_note"`
+	Event     string    `gorm:"column:event"`
+	// NoteIdentifier is null for test deliveries, which are never deduplicated
+	NoteIdentifier     sql.NullString `gorm:"column:note_identifier;uniqueIndex:index_webhook_delivery_note"`
+	Payload            datatypes.JSON `gorm:"column:payload"`
+	Status             string         `gorm:"column:status;index:index_webhook_delivery_status_next_attempt_at"`
+	Attempts           int            `gorm:"column:attempts"`
+	NextAttemptAt      time.Time      `gorm:"column:next_attempt_at;index:index_webhook_delivery_status_next_attempt_at"`
+	ResponseStatusCode int            `gorm:"column:response_status_code"`
+	// ResponseLatency is in milliseconds, the response body is never kept
+	ResponseLatency int64  `gorm:"column:response_latency"`
+	Error           string `gorm:"column:error"`
+
+	common.Table
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_delivery"
+}
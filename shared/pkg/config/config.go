@@ -28,6 +28,9 @@ type ServerStruct struct {
 	HttpPort     int           `koanf:"http_port"`
 	ReadTimeout  time.Duration `koanf:"read_timeout"`
 	WriteTimeout time.Duration `koanf:"write_timeout"`
+	// TrustedProxies are the CIDRs of the ingress whose X-Forwarded-For is believed,
+	// the client address is the peer of the connection if it is empty
+	TrustedProxies []string `koanf:"trusted_proxies"`
 }
 
 type RedisStruct struct {
@@ -91,6 +94,10 @@ type NetWorkStruct struct {
 
 type MoralisStruct struct {
 	ApiKey string `koanf:"api_key"`
+	// RateLimit is the weight of the requests allowed a minute until Moralis reports its own
+	RateLimit int `koanf:"rate_limit"`
+	// InteractiveReserve is the share of the rate limit kept for the crawls a user is waiting for
+	InteractiveReserve float64 `koanf:"interactive_reserve"`
 }
 
 type EtherScanStruct struct {
@@ -128,7 +135,9 @@ type InfuraStruct struct {
 }
 
 type IndexerStruct struct {
-	Server ServerStruct `koanf:"server"`
+	Server  ServerStruct  `koanf:"server"`
+	Crawler CrawlerStruct `koanf:"crawler"`
+	Jobs    JobsStruct    `koanf:"jobs"`
 
 	Misc        MiscStruct        `koanf:"misc"`
 	Jike        JikeStruct        `koanf:"jike"`
@@ -141,15 +150,61 @@ type IndexerStruct struct {
 	Infura      InfuraStruct      `koanf:"infura"`
 }
 
+type CrawlerStruct struct {
+	// Timeout bounds a crawl of one network, in seconds
+	Timeout time.Duration `koanf:"timeout"`
+	// NetworkTimeouts overrides Timeout by network symbol, e.g. slow chains
+	NetworkTimeouts map[string]time.Duration `koanf:"network_timeouts"`
+}
+
+type JobsStruct struct {
+	// Workers is the number of crawl jobs an indexer runs at once, a default if zero, negative disables the worker pool
+	Workers int `koanf:"workers"`
+	// DedupWindow is how long a finished job of an account is returned instead of a new one, in seconds
+	DedupWindow time.Duration `koanf:"dedup_window"`
+	// Retention is how long the result of a job can be polled, in seconds
+	Retention time.Duration `koanf:"retention"`
+}
+
 type HubStruct struct {
-	Server          ServerStruct `koanf:"server"`
-	IndexerEndpoint string       `koanf:"indexer_endpoint"`
+	Server          ServerStruct      `koanf:"server"`
+	IndexerEndpoint string            `koanf:"indexer_endpoint"`
+	ActivityPub     ActivityPubStruct `koanf:"activitypub"`
+	// CursorSecret signs the pagination cursors, it must be shared by all the hub instances and is required outside of debug mode
+	CursorSecret string        `koanf:"cursor_secret"`
+	APIKey       APIKeyStruct  `koanf:"api_key"`
+	Webhook      WebhookStruct `koanf:"webhook"`
+}
+
+type WebhookStruct struct {
+	// AllowPrivateNetworks lets webhooks reach loopback and private addresses, e.g. a local receiver in development
+	AllowPrivateNetworks bool `koanf:"allow_private_networks"`
+}
+
+type APIKeyStruct struct {
+	// Required rejects requests without an API key, otherwise they are limited by IP address
+	Required bool `koanf:"required"`
+	// CrawlLimit and ReadLimit are the default requests per minute, zero means unlimited.
+	// Crawl requests may trigger the indexer, e.g. the first page of notes.
+	CrawlLimit int `koanf:"crawl_limit"`
+	ReadLimit  int `koanf:"read_limit"`
+}
+
+type ActivityPubStruct struct {
+	// Domain is the host of actor handles, the request host is used if empty,
+	// but notes are only delivered to followers if it is set
+	Domain string `koanf:"domain"`
+	// PrivateKey is a PEM encoded RSA key signing outgoing activities
+	PrivateKey string `koanf:"private_key"`
 }
 
 type ElasticsearchStruct struct {
+	// Address disables search if empty
 	Address  string `koanf:"address"`
 	Username string `koanf:"username"`
 	Password string `koanf:"password"`
+	// Index holds the documents of notes and assets
+	Index string `koanf:"index"`
 }
 
 type SentryStruct struct {
@@ -206,8 +261,13 @@ func Setup() error {
 	Config.Postgres.ConnMaxIdleTime = Config.Postgres.ConnMaxIdleTime * time.Second
 	Config.Postgres.ConnMaxLifetime = Config.Postgres.ConnMaxLifetime * time.Second
 
-	Config.Indexer.Server.ReadTimeout = Config.Indexer.Server.ReadTimeout * time.Second
-	Config.Indexer.Server.WriteTimeout = Config.Indexer.Server.WriteTimeout * time.Second
+	Config.Indexer.Jobs.DedupWindow = Config.Indexer.Jobs.DedupWindow * time.Second
+	Config.Indexer.Jobs.Retention = Config.Indexer.Jobs.Retention * time.Second
+
+	Config.Indexer.Crawler.Timeout = Config.Indexer.Crawler.Timeout * time.Second
+	for network, timeout := range Config.Indexer.Crawler.NetworkTimeouts {
+		Config.Indexer.Crawler.NetworkTimeouts[network] = timeout * time.Second
+	}
 
 	return nil
 }
@@ -0,0 +1,239 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/go-redis/redis/v8"
+	jsoniter "github.com/json-iterator/go"
+	"gorm.io/gorm"
+)
+
+// Timeout bounds every check, the probes of the deployments wait a little longer
+const Timeout = 2 * time.Second
+
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+var (
+	ErrNotSetup = errors.New("not set up")
+
+	jsoni = jsoniter.ConfigCompatibleWithStandardLibrary
+)
+
+// Check is a dependency which must be reachable for the service to be ready.
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+	// Optional checks are reported without failing the readiness,
+	// e.g. for a dependency only some of the requests need
+	Optional bool
+}
+
+type CheckResult struct {
+	Status   string `json:"status"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Run runs the checks concurrently, each of them is cancelled after the timeout.
+func Run(ctx context.Context, timeout time.Duration, checks ...Check) Report {
+	report := Report{
+		Status: StatusOK,
+		Checks: make(map[string]CheckResult, len(checks)),
+	}
+
+	mutex := sync.Mutex{}
+	waitGroup := sync.WaitGroup{}
+
+	for _, check := range checks {
+		check := check
+
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+
+			result := run(ctx, timeout, check)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			report.Checks[check.Name] = result
+
+			if result.Status != StatusOK && !check.Optional {
+				report.Status = StatusError
+			}
+		}()
+	}
+
+	waitGroup.Wait()
+
+	return report
+}
+
+func run(ctx context.Context, timeout time.Duration, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	errChan := make(chan error, 1)
+
+	// Some clients ignore the context, the result is abandoned once it is done
+	go func() {
+		errChan <- check.Check(ctx)
+	}()
+
+	var err error
+
+	select {
+	case err = <-errChan:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{
+		Status:   StatusOK,
+		Duration: time.Since(start).String(),
+	}
+
+	if err != nil {
+		result.Status = StatusError
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// LivenessHandler only tells that the process serves requests,
+// restarting it would not fix any of its dependencies.
+func LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		write(w, Report{Status: StatusOK})
+	})
+}
+
+// ReadinessHandler responds 503 unless all the checks pass, optional ones aside.
+func ReadinessHandler(checks ...Check) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		write(w, Run(r.Context(), Timeout, checks...))
+	})
+}
+
+func write(w http.ResponseWriter, report Report) {
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	body, err := jsoni.Marshal(report)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// Postgres pings the database, the clients are got when the checks run as they might be set up later.
+func Postgres(db func() *gorm.DB) Check {
+	return Check{
+		Name: "postgres",
+		Check: func(ctx context.Context) error {
+			gormDB := db()
+			if gormDB == nil {
+				return ErrNotSetup
+			}
+
+			sqlDB, err := gormDB.DB()
+			if err != nil {
+				return err
+			}
+
+			return sqlDB.PingContext(ctx)
+		},
+	}
+}
+
+func Redis(client func() *redis.Client) Check {
+	return Check{
+		Name: "redis",
+		Check: func(ctx context.Context) error {
+			redisClient := client()
+			if redisClient == nil {
+				return ErrNotSetup
+			}
+
+			return redisClient.Ping(ctx).Err()
+		},
+	}
+}
+
+// HTTP expects a 2xx response of the url.
+func HTTP(name string, url string) Check {
+	return Check{
+		Name: name,
+		Check: func(ctx context.Context) error {
+			request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if err != nil {
+				return err
+			}
+
+			response, err := http.DefaultClient.Do(request)
+			if err != nil {
+				return err
+			}
+
+			defer response.Body.Close()
+
+			if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+				return fmt.Errorf("unexpected status %d", response.StatusCode)
+			}
+
+			return nil
+		},
+	}
+}
+
+// Optional reports the check without failing the readiness.
+func Optional(check Check) Check {
+	check.Optional = true
+
+	return check
+}
+
+// JSONRPC asks the node behind the endpoint for its latest block.
+func JSONRPC(name string, endpoint string) Check {
+	return Check{
+		Name: name,
+		Check: func(ctx context.Context) error {
+			client, err := rpc.DialContext(ctx, endpoint)
+			if err != nil {
+				return err
+			}
+
+			defer client.Close()
+
+			var blockNumber string
+
+			return client.CallContext(ctx, &blockNumber, "eth_blockNumber")
+		},
+	}
+}
@@ -0,0 +1,64 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	report := health.Run(context.Background(), 50*time.Millisecond,
+		health.Check{Name: "ok", Check: func(ctx context.Context) error { return nil }},
+		health.Check{Name: "failed", Check: func(ctx context.Context) error { return errors.New("connection refused") }},
+		// A check which ignores the context is abandoned
+		health.Check{Name: "slow", Check: func(ctx context.Context) error {
+			time.Sleep(time.Second)
+
+			return nil
+		}},
+	)
+
+	assert.Equal(t, health.StatusError, report.Status)
+	assert.Equal(t, health.StatusOK, report.Checks["ok"].Status)
+	assert.Equal(t, "connection refused", report.Checks["failed"].Error)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+
+	report = health.Run(context.Background(), 50*time.Millisecond,
+		health.Check{Name: "ok", Check: func(ctx context.Context) error { return nil }},
+		health.Optional(health.Check{Name: "failed", Check: func(ctx context.Context) error { return errors.New("connection refused") }}),
+	)
+
+	assert.Equal(t, health.StatusOK, report.Status)
+	assert.Equal(t, health.StatusError, report.Checks["failed"].Status)
+}
+
+func TestReadinessHandler(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(health.LivenessHandler())
+	defer server.Close()
+
+	recorder := httptest.NewRecorder()
+	health.ReadinessHandler(health.HTTP("upstream", server.URL)).
+		ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"upstream":{"status":"ok"`)
+
+	server.Close()
+
+	recorder = httptest.NewRecorder()
+	health.ReadinessHandler(health.HTTP("upstream", server.URL)).
+		ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"status":"error"`)
+}
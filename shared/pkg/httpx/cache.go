@@ -32,6 +32,19 @@ func getCache(url, method, data string) (string, bool) {
 	}
 }
 
+// GetCache returns the cached response of a GET of the url, which has no headers.
+func GetCache(url string) (Response, bool) {
+	body, ok := getCache(url, methodGet, "")
+	if !ok {
+		return Response{}, false
+	}
+
+	resp := NewResponse()
+	resp.Body = []byte(body)
+
+	return *resp, true
+}
+
 func setCache(url, method, data, response string) error {
 	key := getCacheKey(method, url, data)
 
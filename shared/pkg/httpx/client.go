@@ -1,6 +1,7 @@
 package httpx
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -23,6 +24,15 @@ type Response struct {
 	Header http.Header
 }
 
+// StatusError is returned if the status of a response is not 200 OK.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("StatusCode [%d]", e.StatusCode)
+}
+
 func NewResponse() *Response {
 	return &Response{
 		Body:   []byte{},
@@ -31,14 +41,24 @@ func NewResponse() *Response {
 }
 
 func NoCacheGet(url string, headers map[string]string) (Response, error) {
-	return get(url, headers, false)
+	return get(context.Background(), url, headers, false)
 }
 
 func Get(url string, headers map[string]string) (Response, error) {
-	return get(url, headers, true)
+	return get(context.Background(), url, headers, true)
 }
 
-func get(url string, headers map[string]string, useCache bool) (Response, error) {
+// NoCacheGetWithContext is NoCacheGet bound to ctx, the request is aborted once ctx is done.
+func NoCacheGetWithContext(ctx context.Context, url string, headers map[string]string) (Response, error) {
+	return get(ctx, url, headers, false)
+}
+
+// GetWithContext is Get bound to ctx, the request is aborted once ctx is done.
+func GetWithContext(ctx context.Context, url string, headers map[string]string) (Response, error) {
+	return get(ctx, url, headers, true)
+}
+
+func get(ctx context.Context, url string, headers map[string]string, useCache bool) (Response, error) {
 	resp := NewResponse()
 
 	if useCache {
@@ -76,19 +96,21 @@ func get(url string, headers map[string]string, useCache bool) (Response, error)
 			SetCommonHeader(headers)
 		}
 
-		request := client.R().EnableTrace().SetHeaders(headers)
+		request := client.R().SetContext(ctx).EnableTrace().SetHeaders(headers)
 
 		urlResp, err := request.Get(url)
 		if err != nil {
 			return *resp, err
 		}
 
+		// The headers of a failed response may still tell the rate limits
+		resp.Header = urlResp.Header()
+
 		if urlResp.StatusCode() != 200 {
-			return *resp, fmt.Errorf("StatusCode [%d]", urlResp.StatusCode())
+			return *resp, &StatusError{StatusCode: urlResp.StatusCode()}
 		}
 
 		resp.Body = urlResp.Body()
-		resp.Header = urlResp.Header()
 	}
 
 	if useCache {
@@ -101,14 +123,19 @@ func get(url string, headers map[string]string, useCache bool) (Response, error)
 }
 
 func NoCachePost(url string, headers map[string]string, data string) (Response, error) {
-	return post(url, headers, data, false)
+	return post(context.Background(), url, headers, data, false)
 }
 
 func Post(url string, headers map[string]string, data string) (Response, error) {
-	return post(url, headers, data, true)
+	return post(context.Background(), url, headers, data, true)
+}
+
+// PostWithContext is Post bound to ctx, the request is aborted once ctx is done.
+func PostWithContext(ctx context.Context, url string, headers map[string]string, data string) (Response, error) {
+	return post(ctx, url, headers, data, true)
 }
 
-func post(url string, headers map[string]string, data string, useCache bool) (Response, error) {
+func post(ctx context.Context, url string, headers map[string]string, data string, useCache bool) (Response, error) {
 	resp := NewResponse()
 
 	// get from cache fist
@@ -125,13 +152,16 @@ func post(url string, headers map[string]string, data string, useCache bool) (Re
 		SetCommonHeader(headers)
 	}
 
-	request := client.R().EnableTrace().SetHeaders(headers).SetBody(data)
+	request := client.R().SetContext(ctx).EnableTrace().SetHeaders(headers).SetBody(data)
 
 	// Post url
 	urlResp, err := request.Post(url)
 
-	if cacheErr := setCache(url, methodPost, data, string(urlResp.Body())); cacheErr != nil {
-		logger.Errorf("Failed to set cache for url [%s]. err: %+v", url, cacheErr)
+	// a canceled request has no body worth caching
+	if err == nil {
+		if cacheErr := setCache(url, methodPost, data, string(urlResp.Body())); cacheErr != nil {
+			logger.Errorf("Failed to set cache for url [%s]. err: %+v", url, cacheErr)
+		}
 	}
 
 	resp.Body = urlResp.Body()
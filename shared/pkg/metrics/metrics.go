@@ -0,0 +1,199 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "pregod"
+
+	// routeUnmatched keeps the paths of 404s out of the labels
+	routeUnmatched = "unmatched"
+
+	ResultSuccess = "success"
+	ResultError   = "error"
+
+	APIMoralis = "moralis"
+	APIXscan   = "xscan"
+	APIArweave = "arweave"
+
+	OutcomeRetried      = "retried"
+	OutcomeDeadLettered = "dead_lettered"
+)
+
+var (
+	RequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of HTTP requests by route.",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"method", "route", "status"})
+
+	RequestErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_request_errors_total",
+		Help:      "HTTP requests which failed by route, code is the error code of the response body if any.",
+	}, []string{"method", "route", "code"})
+
+	CrawlDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "crawl_duration_seconds",
+		Help:      "Duration of the crawls of accounts requested by the hub.",
+		Buckets:   []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{"latest", "result"})
+
+	ExternalRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "external_requests_total",
+		Help:      "Calls to external APIs such as Moralis, xscan and Arweave.",
+	}, []string{"api", "result"})
+
+	MoralisRateLimit = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "moralis_rate_limit",
+		Help:      "The last x-rate-limit-limit and x-rate-limit-used headers of Moralis.",
+	}, []string{"header"})
+
+	MoralisWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "moralis_wait_seconds",
+		Help:      "Time the requests to Moralis waited for the rate limit by priority.",
+		Buckets:   []float64{0.01, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"priority"})
+
+	DatabaseUpserts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "database_upserts_total",
+		Help:      "Rows upserted by table.",
+	}, []string{"table"})
+
+	CrawlerCheckpoint = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "crawler_checkpoint_block",
+		Help:      "The last block checkpoint of crawlers.",
+	}, []string{"crawler"})
+
+	CrawlerHead = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "crawler_head_block",
+		Help:      "The last known chain head of crawlers.",
+	}, []string{"crawler"})
+
+	CrawlerLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "crawler_lag_blocks",
+		Help:      "Blocks between the chain head and the checkpoint of crawlers.",
+	}, []string{"crawler"})
+
+	CrawlJobFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "crawl_job_failures_total",
+		Help:      "Failed attempts of crawl jobs by error class, and whether the job was retried or dead-lettered.",
+	}, []string{"class", "outcome"})
+)
+
+var (
+	crawlerMutex       sync.Mutex
+	crawlerCheckpoints = map[string]int64{}
+	crawlerHeads       = map[string]int64{}
+)
+
+// Handler serves the metrics of the default registry.
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+// Middleware observes the requests of the router, requests with errors in
+// the context or a status of 500 and above are counted as errors.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = routeUnmatched
+		}
+
+		status := c.Writer.Status()
+
+		RequestDuration.WithLabelValues(c.Request.Method, route, strconv.Itoa(status)).Observe(time.Since(start).Seconds())
+
+		if len(c.Errors) > 0 || status >= http.StatusInternalServerError {
+			RequestErrors.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.GetInt("code"))).Inc()
+		}
+	}
+}
+
+// ObserveCrawl records the duration since start.
+func ObserveCrawl(start time.Time, latest bool, err error) {
+	CrawlDuration.WithLabelValues(strconv.FormatBool(latest), result(err)).Observe(time.Since(start).Seconds())
+}
+
+// CountExternalRequest counts the calls to an external API by whether they succeeded.
+func CountExternalRequest(api string, err error) {
+	ExternalRequests.WithLabelValues(api, result(err)).Inc()
+}
+
+// ObserveMoralisWait records the wait since start of a request to Moralis.
+func ObserveMoralisWait(priority string, start time.Time) {
+	MoralisWait.WithLabelValues(priority).Observe(time.Since(start).Seconds())
+}
+
+// CountUpserts counts the rows affected by the upserts into the table.
+func CountUpserts(table string, count int64) {
+	DatabaseUpserts.WithLabelValues(table).Add(float64(count))
+}
+
+// SetCrawlerCheckpoint also updates the lag of the crawler once its head is known.
+func SetCrawlerCheckpoint(crawler string, block int64) {
+	crawlerMutex.Lock()
+	defer crawlerMutex.Unlock()
+
+	crawlerCheckpoints[crawler] = block
+	CrawlerCheckpoint.WithLabelValues(crawler).Set(float64(block))
+
+	setCrawlerLag(crawler)
+}
+
+// SetCrawlerHead also updates the lag of the crawler once its checkpoint is known.
+func SetCrawlerHead(crawler string, block int64) {
+	crawlerMutex.Lock()
+	defer crawlerMutex.Unlock()
+
+	crawlerHeads[crawler] = block
+	CrawlerHead.WithLabelValues(crawler).Set(float64(block))
+
+	setCrawlerLag(crawler)
+}
+
+func setCrawlerLag(crawler string) {
+	checkpoint, checkpointExists := crawlerCheckpoints[crawler]
+	head, headExists := crawlerHeads[crawler]
+
+	if checkpointExists && headExists {
+		CrawlerLag.WithLabelValues(crawler).Set(float64(head - checkpoint))
+	}
+}
+
+// CountCrawlJobFailure counts a failed attempt of a crawl job.
+func CountCrawlJobFailure(class string, outcome string) {
+	CrawlJobFailures.WithLabelValues(class, outcome).Inc()
+}
+
+func result(err error) string {
+	if err != nil {
+		return ResultError
+	}
+
+	return ResultSuccess
+}
@@ -0,0 +1,56 @@
+package metrics_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrawlerLag(t *testing.T) {
+	t.Parallel()
+
+	metrics.SetCrawlerCheckpoint("test_lag", 100)
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.CrawlerLag.WithLabelValues("test_lag")))
+
+	metrics.SetCrawlerHead("test_lag", 150)
+	assert.Equal(t, float64(50), testutil.ToFloat64(metrics.CrawlerLag.WithLabelValues("test_lag")))
+
+	metrics.SetCrawlerCheckpoint("test_lag", 140)
+	assert.Equal(t, float64(10), testutil.ToFloat64(metrics.CrawlerLag.WithLabelValues("test_lag")))
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(metrics.Middleware())
+	router.GET("/test/:id", func(c *gin.Context) {
+		if c.Param("id") == "error" {
+			c.Set("code", 10003)
+			_ = c.Error(errors.New("database error"))
+		}
+
+		c.Status(http.StatusOK)
+	})
+	router.GET("/metrics", metrics.Handler())
+
+	for _, path := range []string{"/test/1", "/test/2", "/test/error", "/unknown"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.RequestErrors.WithLabelValues(http.MethodGet, "/test/:id", "10003")))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Contains(t, recorder.Body.String(), `pregod_http_request_duration_seconds_count{method="GET",route="/test/:id",status="200"} 3`)
+	assert.Contains(t, recorder.Body.String(), `route="unmatched",status="404"`)
+}
@@ -0,0 +1,61 @@
+package search
+
+import (
+	"encoding/json"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/datatype"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/database/model"
+	"gorm.io/datatypes"
+)
+
+// attachmentTypeBody holds the full text of an item, e.g. the markdown of a Mirror entry
+const attachmentTypeBody = "body"
+
+func NoteDocument(note model.Note) Document {
+	return Document{
+		Identifier:  note.Identifier,
+		Kind:        KindNote,
+		Owner:       note.Owner,
+		Title:       note.Title,
+		Summary:     note.Summary,
+		Body:        getBody(note.Attachments),
+		Tags:        note.Tags,
+		Authors:     note.Authors,
+		Network:     note.MetadataNetwork,
+		Source:      note.Source,
+		DateCreated: note.DateCreated,
+	}
+}
+
+func AssetDocument(asset model.Asset) Document {
+	return Document{
+		Identifier:  asset.Identifier,
+		Kind:        KindAsset,
+		Owner:       asset.Owner,
+		Title:       asset.Title,
+		Summary:     asset.Summary,
+		Body:        getBody(asset.Attachments),
+		Tags:        asset.Tags,
+		Authors:     asset.Authors,
+		Network:     asset.MetadataNetwork,
+		Source:      asset.Source,
+		DateCreated: asset.DateCreated,
+	}
+}
+
+func getBody(value datatypes.JSON) string {
+	attachments := datatype.Attachments{}
+
+	// Items with malformed attachments are still searchable by their other fields
+	if err := json.Unmarshal(value, &attachments); err != nil {
+		return ""
+	}
+
+	for _, attachment := range attachments {
+		if attachment.Type == attachmentTypeBody {
+			return attachment.Content
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,285 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/config"
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+const defaultIndex = "pregod-items"
+
+var _ Backend = &Elasticsearch{}
+
+// mapping keeps the filtered fields as keywords, so they are matched exactly
+var mapping = map[string]any{
+	"mappings": map[string]any{
+		"properties": map[string]any{
+			"identifier":   map[string]any{"type": "keyword"},
+			"kind":         map[string]any{"type": "keyword"},
+			"owner":        map[string]any{"type": "keyword"},
+			"title":        map[string]any{"type": "text"},
+			"summary":      map[string]any{"type": "text"},
+			"body":         map[string]any{"type": "text"},
+			"tags":         map[string]any{"type": "keyword"},
+			"authors":      map[string]any{"type": "keyword"},
+			"network":      map[string]any{"type": "keyword"},
+			"source":       map[string]any{"type": "keyword"},
+			"date_created": map[string]any{"type": "date"},
+		},
+	},
+}
+
+type Elasticsearch struct {
+	client *elasticsearch.Client
+	index  string
+
+	indexMutex   sync.Mutex
+	indexCreated bool
+}
+
+// NewElasticsearch doesn't connect, the index is created on the first indexing if it doesn't exist.
+func NewElasticsearch(elasticsearchConfig config.ElasticsearchStruct) (*Elasticsearch, error) {
+	client, err := elasticsearch.NewClient(elasticsearch.Config{
+		Addresses: strings.Split(elasticsearchConfig.Address, ","),
+		Username:  elasticsearchConfig.Username,
+		Password:  elasticsearchConfig.Password,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	backend := &Elasticsearch{
+		client: client,
+		index:  elasticsearchConfig.Index,
+	}
+
+	if backend.index == "" {
+		backend.index = defaultIndex
+	}
+
+	return backend, nil
+}
+
+// createIndex is retried by the next indexing until it succeeds.
+func (e *Elasticsearch) createIndex(ctx context.Context) error {
+	e.indexMutex.Lock()
+	defer e.indexMutex.Unlock()
+
+	if e.indexCreated {
+		return nil
+	}
+
+	if err := e.createIndexIfNotExists(ctx); err != nil {
+		return err
+	}
+
+	e.indexCreated = true
+
+	return nil
+}
+
+func (e *Elasticsearch) createIndexIfNotExists(ctx context.Context) error {
+	response, err := e.client.Indices.Exists([]string{e.index}, e.client.Indices.Exists.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+	default:
+		return fmt.Errorf("elasticsearch responded %s", response.Status())
+	}
+
+	body, err := json.Marshal(mapping)
+	if err != nil {
+		return err
+	}
+
+	if response, err = e.client.Indices.Create(
+		e.index, e.client.Indices.Create.WithContext(ctx), e.client.Indices.Create.WithBody(bytes.NewReader(body)),
+	); err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	return checkResponse(response)
+}
+
+// Index upserts the documents by identifier with the bulk API.
+func (e *Elasticsearch) Index(ctx context.Context, documents []Document) error {
+	if len(documents) == 0 {
+		return nil
+	}
+
+	if err := e.createIndex(ctx); err != nil {
+		return err
+	}
+
+	body := bytes.Buffer{}
+	encoder := json.NewEncoder(&body)
+
+	for _, document := range documents {
+		action := map[string]any{
+			"index": map[string]any{"_index": e.index, "_id": document.Identifier},
+		}
+
+		if err := encoder.Encode(action); err != nil {
+			return err
+		}
+
+		if err := encoder.Encode(document); err != nil {
+			return err
+		}
+	}
+
+	response, err := e.client.Bulk(&body, e.client.Bulk.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if err := checkResponse(response); err != nil {
+		return err
+	}
+
+	// The bulk API reports the errors of every document with 200
+	result := struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID    string          `json:"_id"`
+			Error json.RawMessage `json:"error"`
+		} `json:"items"`
+	}{}
+
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return err
+	}
+
+	if !result.Errors {
+		return nil
+	}
+
+	for _, item := range result.Items {
+		for _, action := range item {
+			if len(action.Error) > 0 {
+				return fmt.Errorf("index %s: %s", action.ID, action.Error)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (e *Elasticsearch) Search(ctx context.Context, query Query) (*Result, error) {
+	body, err := json.Marshal(buildQuery(query))
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := e.client.Search(
+		e.client.Search.WithContext(ctx),
+		e.client.Search.WithIndex(e.index),
+		e.client.Search.WithBody(bytes.NewReader(body)),
+		e.client.Search.WithTrackTotalHits(true),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if err := checkResponse(response); err != nil {
+		return nil, err
+	}
+
+	searchResult := struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				Source Hit `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}{}
+
+	if err := json.NewDecoder(response.Body).Decode(&searchResult); err != nil {
+		return nil, err
+	}
+
+	result := Result{
+		Total: searchResult.Hits.Total.Value,
+		Hits:  make([]Hit, 0, len(searchResult.Hits.Hits)),
+	}
+
+	for _, hit := range searchResult.Hits.Hits {
+		result.Hits = append(result.Hits, hit.Source)
+	}
+
+	return &result, nil
+}
+
+// buildQuery builds the body of a search request
+func buildQuery(query Query) map[string]any {
+	filters := make([]any, 0)
+
+	for _, filter := range []struct {
+		field  string
+		values []string
+	}{
+		{"kind", query.Kinds},
+		{"owner", query.Owners},
+		{"tags", query.Tags},
+		{"network", query.Networks},
+	} {
+		if len(filter.values) > 0 {
+			filters = append(filters, map[string]any{"terms": map[string]any{filter.field: filter.values}})
+		}
+	}
+
+	boolQuery := map[string]any{"filter": filters}
+	sort := []any{map[string]any{"date_created": "desc"}, map[string]any{"identifier": "asc"}}
+
+	if query.Text != "" {
+		boolQuery["must"] = map[string]any{
+			"multi_match": map[string]any{
+				"query":  query.Text,
+				"fields": []string{"title^3", "summary^2", "body", "tags", "authors"},
+			},
+		}
+		sort = append([]any{"_score"}, sort...)
+	}
+
+	return map[string]any{
+		"from":    query.Offset,
+		"size":    query.Limit,
+		"query":   map[string]any{"bool": boolQuery},
+		"sort":    sort,
+		"_source": []string{"identifier", "kind"},
+	}
+}
+
+func checkResponse(response *esapi.Response) error {
+	if !response.IsError() {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+
+	return fmt.Errorf("elasticsearch responded %s: %s", response.Status(), body)
+}
@@ -0,0 +1,103 @@
+package search
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var _ Backend = &Memory{}
+
+// Memory is a Backend for tests, it matches documents containing every word of the text.
+type Memory struct {
+	mutex     sync.RWMutex
+	documents map[string]Document
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		documents: map[string]Document{},
+	}
+}
+
+func (m *Memory) Index(_ context.Context, documents []Document) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, document := range documents {
+		m.documents[document.Identifier] = document
+	}
+
+	return nil
+}
+
+func (m *Memory) Search(_ context.Context, query Query) (*Result, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	documents := make([]Document, 0)
+
+	for _, document := range m.documents {
+		if query.matches(document) {
+			documents = append(documents, document)
+		}
+	}
+
+	sort.Slice(documents, func(i, j int) bool {
+		if !documents[i].DateCreated.Equal(documents[j].DateCreated) {
+			return documents[i].DateCreated.After(documents[j].DateCreated)
+		}
+
+		return documents[i].Identifier < documents[j].Identifier
+	})
+
+	result := Result{
+		Total: int64(len(documents)),
+		Hits:  make([]Hit, 0),
+	}
+
+	for i := query.Offset; i < len(documents) && i < query.Offset+query.Limit; i++ {
+		result.Hits = append(result.Hits, Hit{Identifier: documents[i].Identifier, Kind: documents[i].Kind})
+	}
+
+	return &result, nil
+}
+
+func (q Query) matches(document Document) bool {
+	if !containsAny(q.Kinds, document.Kind) || !containsAny(q.Owners, document.Owner) ||
+		!containsAny(q.Networks, document.Network) || !containsAny(q.Tags, document.Tags...) {
+		return false
+	}
+
+	fields := []string{document.Title, document.Summary, document.Body}
+	fields = append(fields, document.Tags...)
+	fields = append(fields, document.Authors...)
+
+	text := strings.ToLower(strings.Join(fields, " "))
+
+	for _, word := range strings.Fields(strings.ToLower(q.Text)) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// containsAny reports whether any value is in the filter, an empty filter contains everything
+func containsAny(filter []string, values ...string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+
+	for _, value := range values {
+		for _, item := range filter {
+			if item == value {
+				return true
+			}
+		}
+	}
+
+	return false
+}
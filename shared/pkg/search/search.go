@@ -0,0 +1,111 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/config"
+	"github.com/NaturalSelectionLabs/RSS3-PreGod/shared/pkg/logger"
+)
+
+const (
+	KindNote  = "note"
+	KindAsset = "asset"
+
+	// MaxResultWindow is the default index.max_result_window of Elasticsearch, offset and limit can't go beyond it
+	MaxResultWindow = 10000
+)
+
+var ErrDisabled = errors.New("search is disabled")
+
+// Document mirrors the searchable fields of a note or an asset.
+type Document struct {
+	Identifier  string    `json:"identifier"`
+	Kind        string    `json:"kind"`
+	Owner       string    `json:"owner"`
+	Title       string    `json:"title"`
+	Summary     string    `json:"summary"`
+	Body        string    `json:"body"`
+	Tags        []string  `json:"tags"`
+	Authors     []string  `json:"authors"`
+	Network     string    `json:"network"`
+	Source      string    `json:"source"`
+	DateCreated time.Time `json:"date_created"`
+}
+
+// Query matches Text against the text fields of the documents, the other fields are filters.
+// Documents are sorted by relevance then by creation date, or only by creation date without Text.
+type Query struct {
+	Text     string
+	Kinds    []string
+	Owners   []string
+	Tags     []string
+	Networks []string
+	Offset   int
+	Limit    int
+}
+
+type Hit struct {
+	Identifier string `json:"identifier"`
+	Kind       string `json:"kind"`
+}
+
+type Result struct {
+	Total int64
+	Hits  []Hit
+}
+
+// Backend must not keep the documents passed to Index, the slice is reused.
+// Index upserts the documents by identifier, indexing them again is harmless.
+type Backend interface {
+	Index(ctx context.Context, documents []Document) error
+	Search(ctx context.Context, query Query) (*Result, error)
+}
+
+var defaultBackend Backend
+
+// Setup creates the client of the configured Elasticsearch, search stays disabled without an address.
+// It doesn't connect, an unavailable cluster only fails the searches and delays the indexing.
+func Setup() error {
+	if config.Config.Elasticsearch.Address == "" {
+		logger.Warn("elasticsearch address is empty, search is disabled")
+
+		return nil
+	}
+
+	backend, err := NewElasticsearch(config.Config.Elasticsearch)
+	if err != nil {
+		return err
+	}
+
+	SetBackend(backend)
+
+	return nil
+}
+
+// SetBackend replaces the backend, it must be called before indexing or searching.
+func SetBackend(backend Backend) {
+	defaultBackend = backend
+}
+
+func Enabled() bool {
+	return defaultBackend != nil
+}
+
+// Index upserts the documents right away, the caller keeps track of what is indexed.
+func Index(ctx context.Context, documents []Document) error {
+	if defaultBackend == nil {
+		return ErrDisabled
+	}
+
+	return defaultBackend.Index(ctx, documents)
+}
+
+func Search(ctx context.Context, query Query) (*Result, error) {
+	if defaultBackend == nil {
+		return nil, ErrDisabled
+	}
+
+	return defaultBackend.Search(ctx, query)
+}